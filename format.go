@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+// /**
+// * @brief Runs the fmt subcommand: jsonparser fmt [-w] [-check] [-strip-comments] file...
+// *
+// * @details Like gofmt, each file is reformatted with FormatSource and printed to stdout. With -w the
+// * result is written back to files it changes instead, and with -check nothing is written: the names
+// * of files that are not formatted are listed and the exit status is 1 if there are any.
+// *
+// * @param args The arguments after "fmt".
+// * @param stdout The writer receiving formatted text and file names.
+// * @param stderr The writer receiving usage and errors.
+// * @return The process exit status.
+// */
+func runFmt(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write the result back to each file instead of printing it")
+	check := flags.Bool("check", false, "list files whose formatting differs and exit with status 1 if any do")
+	strip := flags.Bool("strip-comments", false, "drop comments instead of keeping them (output is plain JSON)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonparser fmt [-w] [-check] [-strip-comments] file...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			status = 2
+			continue
+		}
+		formatted, err := jsonparser.FormatSource(string(data), !*strip)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			status = 2
+			continue
+		}
+		changed := formatted != string(data)
+		switch {
+		case *check:
+			if changed {
+				fmt.Fprintln(stdout, path)
+				if status == 0 {
+					status = 1
+				}
+			}
+		case *write:
+			if !changed {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				fmt.Fprintf(stderr, "%v\n", err)
+				status = 2
+				continue
+			}
+			if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+				fmt.Fprintf(stderr, "%v\n", err)
+				status = 2
+			}
+		default:
+			io.WriteString(stdout, formatted)
+		}
+	}
+	return status
+}
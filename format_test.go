@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	unformatted = "{\"b\": 1,\n\"a\": [1,2]}"
+	formatted   = "{\n  \"a\": [1, 2],\n  \"b\": 1\n}\n"
+)
+
+func TestRunFmt(t *testing.T) {
+	tests := []struct {
+		name       string
+		flags      []string
+		content    string
+		wantStatus int
+		wantStdout string
+		wantStderr string
+		wantFile   string
+	}{
+		{"print", nil, unformatted, 0, formatted, "", unformatted},
+		{"write changes the file", []string{"-w"}, unformatted, 0, "", "", formatted},
+		{"write leaves a formatted file alone", []string{"-w"}, formatted, 0, "", "", formatted},
+		{"check lists an unformatted file", []string{"-check"}, unformatted, 1, "doc.json\n", "", unformatted},
+		{"check passes a formatted file", []string{"-check"}, formatted, 0, "", "", formatted},
+		{"check wins over write", []string{"-check", "-w"}, unformatted, 1, "doc.json\n", "", unformatted},
+		{"strip comments", []string{"-w", "-strip-comments"}, "{\n  // note\n  \"a\": 1\n}\n", 0, "", "", "{\n  \"a\": 1\n}\n"},
+		{"keep comments", []string{"-w"}, "{\"a\": 1 // note\n}", 0, "", "", "{\n  // note\n  \"a\": 1\n}\n"},
+		{"write keeps empty containers compact", []string{"-w"}, `{"b":[],"a":{}}`, 0, "", "", "{\n  \"a\": {},\n  \"b\": []\n}\n"},
+		{"check passes formatted empty containers", []string{"-check"}, "{\n  \"a\": {},\n  \"b\": []\n}\n", 0, "", "", "{\n  \"a\": {},\n  \"b\": []\n}\n"},
+		{"syntax error", []string{"-w"}, `{"a": }`, 2, "", "line 1, col 7", `{"a": }`},
+		{"duplicate keys are refused", []string{"-w"}, `{"a": 1, "a": 2}`, 2, "", "duplicate keys: /a", `{"a": 1, "a": 2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "doc.json", tt.content)
+			var stdout, stderr strings.Builder
+			status := runFmt(append(tt.flags, path), &stdout, &stderr)
+			if status != tt.wantStatus {
+				t.Errorf("status %d, want %d (stderr %q)", status, tt.wantStatus, stderr.String())
+			}
+			if got := strings.ReplaceAll(stdout.String(), path, "doc.json"); got != tt.wantStdout {
+				t.Errorf("stdout %q, want %q", got, tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) || tt.wantStderr == "" && stderr.Len() > 0 {
+				t.Errorf("stderr %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantFile {
+				t.Errorf("file holds %q, want %q", data, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestRunFmtUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no files", nil},
+		{"unknown flag", []string{"-x", "doc.json"}},
+		{"missing file", []string{"no/such/file.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if status := runFmt(tt.args, &stdout, &stderr); status != 2 {
+				t.Errorf("status %d, want 2", status)
+			}
+			if stderr.Len() == 0 {
+				t.Error("nothing written to stderr")
+			}
+		})
+	}
+}
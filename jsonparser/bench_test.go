@@ -0,0 +1,285 @@
+package jsonparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// corpusWords is the vocabulary of the generated text.
+var corpusWords = strings.Fields("alpha beta gamma delta epsilon zeta eta theta iota kappa lambda mu nu xi omicron pi rho sigma tau upsilon")
+
+// corpusShape builds one kind of benchmark document from a seeded source, so
+// the same size always gives the same text.
+type corpusShape struct {
+	name   string
+	sizes  [3]int // the size parameter for small, medium and large
+	indent bool   // write the document indented, as config files and API dumps are
+	build  func(r *rand.Rand, n int) interface{}
+}
+
+var corpusShapes = []corpusShape{
+	{"nested", [3]int{16, 512, 8192}, false, nestedCorpus},
+	{"wide", [3]int{50, 1500, 10000}, true, wideCorpus},
+	{"records", [3]int{10, 300, 4000}, true, recordsCorpus},
+	{"numbers", [3]int{100, 3000, 20000}, false, numbersCorpus},
+	{"strings", [3]int{10, 250, 3000}, true, stringsCorpus},
+}
+
+var corpusSizes = [3]string{"small", "medium", "large"}
+
+// corpus is a generated benchmark document, named like "records-medium".
+type corpus struct {
+	name string
+	data string
+}
+
+// corpora generates every shape at every size.
+func corpora(tb testing.TB) []corpus {
+	tb.Helper()
+	var out []corpus
+	for _, shape := range corpusShapes {
+		for i, size := range corpusSizes {
+			out = append(out, corpus{shape.name + "-" + size, buildCorpus(tb, shape, shape.sizes[i])})
+		}
+	}
+	return out
+}
+
+func buildCorpus(tb testing.TB, shape corpusShape, n int) string {
+	v := shape.build(rand.New(rand.NewSource(241)), n)
+	var data []byte
+	var err error
+	if shape.indent {
+		data, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return string(data) + "\n"
+}
+
+func corpusText(r *rand.Rand, words int) string {
+	out := make([]string, words)
+	for i := range out {
+		out[i] = corpusWords[r.Intn(len(corpusWords))]
+	}
+	return strings.Join(out, " ")
+}
+
+// nestedCorpus alternates objects and arrays depth levels deep.
+func nestedCorpus(r *rand.Rand, depth int) interface{} {
+	var v interface{} = map[string]interface{}{"leaf": true}
+	for i := depth; i > 0; i-- {
+		if i%2 == 1 {
+			v = map[string]interface{}{"level": i, "name": fmt.Sprintf("node %d", i), "child": v}
+		} else {
+			v = []interface{}{i, fmt.Sprintf("item %d", i), v}
+		}
+	}
+	return v
+}
+
+// wideCorpus is one object with n small members.
+func wideCorpus(r *rand.Rand, n int) interface{} {
+	obj := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		obj[fmt.Sprintf("key%05d", i)] = map[string]interface{}{"id": i, "name": fmt.Sprintf("entry %d", i), "active": i%3 == 0}
+	}
+	return obj
+}
+
+// recordsCorpus is an array of n user records, the shape of a typical API response.
+func recordsCorpus(r *rand.Rand, n int) interface{} {
+	records := make([]interface{}, n)
+	for i := range records {
+		records[i] = map[string]interface{}{
+			"id":       i,
+			"name":     corpusText(r, 2),
+			"email":    fmt.Sprintf("user%d@example.com", i),
+			"age":      18 + r.Intn(73),
+			"score":    math.Round(r.Float64()*10000) / 100,
+			"tags":     []interface{}{corpusText(r, 1), corpusText(r, 1)},
+			"verified": r.Intn(2) == 0,
+			"manager":  nil,
+		}
+	}
+	return records
+}
+
+// numbersCorpus holds long arrays of integers, fractions and exponents.
+func numbersCorpus(r *rand.Rand, n int) interface{} {
+	timestamps, readings, counts := make([]interface{}, n), make([]interface{}, n), make([]interface{}, n)
+	exponents := make([]interface{}, n/4)
+	for i := 0; i < n; i++ {
+		timestamps[i] = 1700000000000 + r.Int63n(1e9)
+		readings[i] = math.Round((r.Float64()*2000-1000)*1e6) / 1e6
+		counts[i] = r.Intn(2000001) - 1000000
+	}
+	for i := range exponents {
+		exponents[i] = r.Float64() * math.Pow(10, float64(r.Intn(25)-12))
+	}
+	return map[string]interface{}{"timestamps": timestamps, "readings": readings, "exponents": exponents, "counts": counts}
+}
+
+// stringsCorpus is text-heavy: documents with escapes, newlines and non-ASCII text.
+func stringsCorpus(r *rand.Rand, n int) interface{} {
+	docs := make([]interface{}, n)
+	for i := range docs {
+		body := corpusText(r, 5+r.Intn(26))
+		if i%7 == 0 {
+			body += ` with "quotes" and \backslashes\`
+		}
+		if i%11 == 0 {
+			body += "\nsecond line\ttabbed"
+		}
+		if i%13 == 0 {
+			body += " café 日本 \U0001F600"
+		}
+		docs[i] = map[string]interface{}{"id": fmt.Sprintf("doc-%d", i), "title": corpusText(r, 4), "body": body}
+	}
+	return docs
+}
+
+// TestCorpora keeps the benchmark documents honest: each must parse, and to
+// the same value encoding/json decodes.
+func TestCorpora(t *testing.T) {
+	for _, c := range corpora(t) {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseJSON(c.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want interface{}
+			if err := json.Unmarshal([]byte(c.data), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(got, want) {
+				t.Error("ParseJSON and encoding/json disagree")
+			}
+		})
+	}
+}
+
+// benchmarkCorpora runs parse over every corpus, generating each in its
+// sub-benchmark's setup.
+func benchmarkCorpora(b *testing.B, parse func(string) error) {
+	for _, shape := range corpusShapes {
+		for i, size := range corpusSizes {
+			n := shape.sizes[i]
+			b.Run(shape.name+"-"+size, func(b *testing.B) {
+				data := buildCorpus(b, shape, n)
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if err := parse(data); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	benchmarkCorpora(b, func(s string) error {
+		_, err := ParseJSON(s)
+		return err
+	})
+}
+
+func BenchmarkParsePreserveIntegers(b *testing.B) {
+	opts := ParseOptions{PreserveIntegers: true}
+	benchmarkCorpora(b, func(s string) error {
+		_, err := ParseJSONWithOptions(s, opts)
+		return err
+	})
+}
+
+func BenchmarkParseDocument(b *testing.B) {
+	opts := ParseOptions{AllowComments: true}
+	benchmarkCorpora(b, func(s string) error {
+		_, err := ParseDocument(s, opts)
+		return err
+	})
+}
+
+func BenchmarkTokenize(b *testing.B) {
+	benchmarkCorpora(b, func(s string) error {
+		_, err := Tokenize(s)
+		return err
+	})
+}
+
+func BenchmarkTokenizeRecover(b *testing.B) {
+	benchmarkCorpora(b, func(s string) error {
+		if _, errs := TokenizeRecover(s); len(errs) > 0 {
+			return &errs[0]
+		}
+		return nil
+	})
+}
+
+// BenchmarkEncodingJSON is the standard library on the same corpora, for scale.
+func BenchmarkEncodingJSON(b *testing.B) {
+	benchmarkCorpora(b, func(s string) error {
+		var v interface{}
+		return json.Unmarshal([]byte(s), &v)
+	})
+}
+
+// wideObjects builds an array of count objects with members members each,
+// the object-heavy shape that presizing maps from objectSizes is for.
+func wideObjects(count, members int) string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("{")
+		for j := 0; j < members; j++ {
+			if j > 0 {
+				sb.WriteString(",")
+			}
+			fmt.Fprintf(&sb, `"k%d":%d`, j, j)
+		}
+		sb.WriteString("}")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// BenchmarkParseWideObjects parses 20 objects of 5000 members from their
+// tokens, with maps presized from objectSizes and with maps grown from empty.
+func BenchmarkParseWideObjects(b *testing.B) {
+	doc := wideObjects(20, 5000)
+	tokens, err := Tokenize(doc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	opts := &ParseOptions{}
+	b.Run("presized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := parse(doc, tokens, opts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unsized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ts := &TokenStream{tokens: tokens, opts: opts, src: doc}
+			if _, err := parseDocuments(ts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package jsonparser
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// / ParserCache memoizes parse results by a SHA-256 hash of the input, so identical documents are parsed
+// / once. It is safe for concurrent use.
+// / Trees returned for the same input are the same shared value: treat them as read-only, and Clone one
+// / before modifying it, or every later caller will see the change.
+type ParserCache struct {
+	opts       ParseOptions
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]interface{} ///< parsed trees by input hash
+	order   [][sha256.Size]byte               ///< hashes in insertion order, oldest first, for eviction
+}
+
+// /**
+// * @brief Creates a cache that parses strict JSON.
+// *
+// * @param maxEntries The most trees to keep; the oldest is dropped to make room (0 = unlimited).
+// * @return The new cache.
+// */
+func NewParserCache(maxEntries int) *ParserCache {
+	return NewParserCacheWithOptions(maxEntries, ParseOptions{})
+}
+
+// /**
+// * @brief Creates a cache that parses with the given options.
+// *
+// * @param maxEntries The most trees to keep; the oldest is dropped to make room (0 = unlimited).
+// * @param opts The parse options used for every input.
+// * @return The new cache.
+// */
+func NewParserCacheWithOptions(maxEntries int, opts ParseOptions) *ParserCache {
+	return &ParserCache{opts: opts, maxEntries: maxEntries, entries: make(map[[sha256.Size]byte]interface{})}
+}
+
+// /**
+// * @brief Parses jsonStr like ParseJSONWithOptions, or returns the tree cached for the same input.
+// *
+// * @details Errors are not cached. Parsing happens outside the lock, so callers racing on a new input
+// * may each parse it once; the first result stored wins.
+// *
+// * @param jsonStr The JSON string to parse.
+// * @return The shared, read-only parsed value or an error.
+// */
+func (c *ParserCache) Parse(jsonStr string) (interface{}, error) {
+	key := sha256.Sum256([]byte(jsonStr))
+	c.mu.Lock()
+	value, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := ParseJSONWithOptions(jsonStr, c.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.entries[key]; ok {
+		return cached, nil
+	}
+	if c.maxEntries > 0 && len(c.order) >= c.maxEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[key] = value
+	c.order = append(c.order, key)
+	return value, nil
+}
+
+// /**
+// * @brief Reports how many parsed trees the cache holds.
+// */
+func (c *ParserCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
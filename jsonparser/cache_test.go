@@ -0,0 +1,80 @@
+package jsonparser
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParserCache(t *testing.T) {
+	c := NewParserCache(2)
+	a, err := c.Parse(`{"a": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := c.Parse(`{"a": 1}`)
+	if reflect.ValueOf(a).Pointer() != reflect.ValueOf(again).Pointer() {
+		t.Error("identical input was parsed again instead of shared")
+	}
+	if _, err := c.Parse(`{"a": `); err == nil {
+		t.Error("invalid input parsed")
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len = %d after an error, want 1", c.Len())
+	}
+	c.Parse(`[1]`)
+	c.Parse(`[2]`)
+	if c.Len() != 2 {
+		t.Errorf("Len = %d, want the limit of 2", c.Len())
+	}
+	evicted, _ := c.Parse(`{"a": 1}`)
+	if reflect.ValueOf(a).Pointer() == reflect.ValueOf(evicted).Pointer() {
+		t.Error("the oldest tree was not evicted")
+	}
+}
+
+func TestParserCacheConcurrent(t *testing.T) {
+	c := NewParserCache(0)
+	docs := []string{`{"a": 1}`, `[1, 2]`, `"s"`, `{"b": [true]}`}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := c.Parse(docs[j%len(docs)]); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Len() != len(docs) {
+		t.Errorf("Len = %d, want %d", c.Len(), len(docs))
+	}
+}
+
+// BenchmarkParserCache parses the same medium document over and over, through
+// a ParserCache and directly.
+func BenchmarkParserCache(b *testing.B) {
+	doc := wideDocument(1000)
+	b.Run("cached", func(b *testing.B) {
+		c := NewParserCache(0)
+		b.SetBytes(int64(len(doc)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.Parse(doc); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.SetBytes(int64(len(doc)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseJSON(doc); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package jsonparser
+
+// / Document is a parsed JSON value together with the comments found in its source.
+type Document struct {
+	Value    interface{}         ///< the parsed JSON value
+	Comments map[string][]string ///< comment texts keyed by the JSON Pointer of the value they document
+}
+
+// /**
+// * @brief Parses a JSON string, keeping its comments as metadata on the values they document.
+// *
+// * @details Comments are only accepted, and so only captured, when opts.AllowComments is set. A comment
+// * on its own line documents the value that follows it; one at the end of a line documents the value on
+// * that line. Comments after the last member of an object or array belong to that container, and
+// * comments after the whole document belong to the root (""). Comments on the same value are kept in
+// * source order.
+// *
+// * @param jsonStr The JSON string to parse.
+// * @param opts The parse options, e.g. LenientOptions().
+// * @return The Document or an error.
+// */
+func ParseDocument(jsonStr string, opts ParseOptions) (*Document, error) {
+	tokens, err := tokenizeWithOptions(jsonStr, &opts)
+	if err != nil {
+		if trailing := trailingText(jsonStr, &opts); trailing != nil {
+			return nil, trailing
+		}
+		return nil, err
+	}
+	ts := &TokenStream{tokens: tokens, opts: &opts, src: jsonStr}
+	if opts.AllowComments {
+		ts.comments = make(map[string][]string)
+	}
+	value, err := parseStream(ts)
+	if err != nil {
+		return nil, err
+	}
+	/// Comments after the document.
+	ts.path = nil
+	ts.markValue()
+	return &Document{Value: value, Comments: ts.comments}, nil
+}
@@ -0,0 +1,50 @@
+package jsonparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDocumentComments(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string][]string
+	}{
+		{"leading comment", "{\n  // the name\n  \"name\": \"x\"\n}", map[string][]string{"/name": {"the name"}}},
+		{"trailing comment", "{\n  \"a\": 1, // one\n  \"b\": 2 /* two */\n}", map[string][]string{"/a": {"one"}, "/b": {"two"}}},
+		{"leading and trailing on one member", "{\n  // above\n  \"a\": 1 // beside\n}", map[string][]string{"/a": {"above", "beside"}}},
+		{"after the last member", "{\n  \"a\": 1\n  // closing\n}", map[string][]string{"": {"closing"}}},
+		{"after the last element of a nested array", "{\"b\": [\n  1\n  // closing\n]}", map[string][]string{"/b": {"closing"}}},
+		{"array elements", "[\n  1, // first\n  // second\n  2\n]", map[string][]string{"/0": {"first"}, "/1": {"second"}}},
+		{"nested member", "{\"c\": {\"d\": 1 /* d */}}", map[string][]string{"/c/d": {"d"}}},
+		{"after a colon", "{\"a\": // value\n  1}", map[string][]string{"/a": {"value"}}},
+		{"key needing escapes", "{\"a/b~\": 1 // escaped\n}", map[string][]string{"/a~1b~0": {"escaped"}}},
+		{"before and after the document", "// top\n1\n// bottom", map[string][]string{"": {"top", "bottom"}}},
+		{"several in source order", "/* a */ /* b */ [\n  // c\n  // d\n  1\n]", map[string][]string{"": {"a", "b"}, "/0": {"c", "d"}}},
+		{"no comments", `{"a": [1]}`, map[string][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(tt.input, ParseOptions{AllowComments: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(doc.Comments, tt.want) {
+				t.Errorf("comments = %q, want %q", doc.Comments, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDocumentWithoutComments(t *testing.T) {
+	doc, err := ParseDocument(`{"a": 1}`, ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Comments != nil {
+		t.Errorf("comments = %q, want nil when AllowComments is off", doc.Comments)
+	}
+	_, err = ParseDocument("// c\n1", ParseOptions{})
+	checkErr(t, err, "unexpected character")
+}
@@ -0,0 +1,71 @@
+package jsonparser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// / Edit replaces the value at a JSON Pointer in a source document.
+type Edit struct {
+	Pointer string      ///< RFC 6901 pointer of the value to replace ("" for the whole document)
+	Value   interface{} ///< the new value
+}
+
+// /**
+// * @brief Rewrites only the values targeted by the edits, leaving the rest of the source byte-identical.
+// *
+// * @details Each target is located in the source text the same way GetRaw finds it, and only its bytes are
+// * replaced, so the surrounding whitespace, member order and number formatting survive. This gives the
+// * minimal diff an editor wants. Comments and trailing commas in the source are skipped over and kept, and
+// * a repeated key is resolved to its last member, as in the parsed tree. New values are written as compact
+// * JSON, as Marshal writes them; objects and arrays therefore end up on one line. Edits can only replace
+// * existing values, and their targets must not overlap (e.g. "/a" and "/a/b" in one call).
+// *
+// * @param source The JSON document.
+// * @param edits The replacements to make.
+// * @return The edited document, or an error if a pointer doesn't resolve, a new value has no JSON form
+// *         (see Marshal) or two edits overlap.
+// */
+func ApplyEdits(source string, edits []Edit) (string, error) {
+	type span struct {
+		start, end int
+		text       string
+	}
+	/// Comments and trailing commas are skipped over like whitespace, so JSONC sources keep them.
+	opts := &ParseOptions{AllowComments: true, AllowTrailingCommas: true}
+	spans := make([]span, 0, len(edits))
+	for _, edit := range edits {
+		refs, err := parsePointer(edit.Pointer)
+		if err != nil {
+			return "", err
+		}
+		start, err := locateValue(source, refs, opts)
+		if err != nil {
+			return "", fmt.Errorf("pointer %q: %v", edit.Pointer, err)
+		}
+		end, err := skipValue(source, start, opts)
+		if err != nil {
+			return "", fmt.Errorf("pointer %q: %v", edit.Pointer, err)
+		}
+		text, err := Marshal(edit.Value)
+		if err != nil {
+			return "", fmt.Errorf("pointer %q: %v", edit.Pointer, err)
+		}
+		spans = append(spans, span{start, end, string(text)})
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var sb strings.Builder
+	last := 0
+	for i, sp := range spans {
+		if sp.start < last {
+			return "", fmt.Errorf("edits %q and %q overlap", source[spans[i-1].start:spans[i-1].end], source[sp.start:sp.end])
+		}
+		sb.WriteString(source[last:sp.start])
+		sb.WriteString(sp.text)
+		last = sp.end
+	}
+	sb.WriteString(source[last:])
+	return sb.String(), nil
+}
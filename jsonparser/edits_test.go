@@ -0,0 +1,56 @@
+package jsonparser
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestApplyEdits(t *testing.T) {
+	const source = `{
+    "name":   "svc",
+    "port": 8080 ,
+    "ratio": 1.50,
+    "hosts": [ "a", "b" ],
+    "nested": {"deep": {"x": 1e3}}
+}
+`
+	tests := []struct {
+		name    string
+		source  string
+		edits   []Edit
+		want    string
+		wantErr string
+	}{
+		{"one number, rest byte-identical", source, []Edit{{"/port", float64(9090)}},
+			strings.Replace(source, "8080", "9090", 1), ""},
+		{"other number formats are kept", source, []Edit{{"/nested/deep/x", int64(2)}},
+			strings.Replace(source, "1e3", "2", 1), ""},
+		{"several edits", source, []Edit{{"/name", "api"}, {"/hosts/1", "c"}, {"/ratio", nil}},
+			strings.NewReplacer(`"svc"`, `"api"`, `"b" ]`, `"c" ]`, "1.50", "null").Replace(source), ""},
+		{"container replaced compactly", source, []Edit{{"/hosts", []interface{}{"x", map[string]interface{}{"y": true}}}},
+			strings.Replace(source, `[ "a", "b" ]`, `["x",{"y":true}]`, 1), ""},
+		{"whole document", ` [1] `, []Edit{{"", "s"}}, ` "s" `, ""},
+		{"escaped pointer token", `{"a/b": 1, "c~d": 2}`, []Edit{{"/a~1b", float64(3)}, {"/c~0d", float64(4)}}, `{"a/b": 3, "c~d": 4}`, ""},
+		{"string needing escapes", `{"s": ""}`, []Edit{{"/s", "a\"b\n"}}, `{"s": "a\"b\n"}`, ""},
+		{"comments are kept", "{\n  // port\n  \"port\": /* old */ 1, // trailing\n}", []Edit{{"/port", float64(2)}},
+			"{\n  // port\n  \"port\": /* old */ 2, // trailing\n}", ""},
+		{"last duplicate key", `{"a": 1, "a": 2}`, []Edit{{"/a", float64(3)}}, `{"a": 1, "a": 3}`, ""},
+		{"no edits", source, nil, source, ""},
+		{"missing member", source, []Edit{{"/missing", nil}}, "", `pointer "/missing"`},
+		{"index out of range", source, []Edit{{"/hosts/5", nil}}, "", `pointer "/hosts/5"`},
+		{"overlapping edits", source, []Edit{{"/nested", nil}, {"/nested/deep", nil}}, "", "overlap"},
+		{"invalid pointer", source, []Edit{{"port", nil}}, "", "pointer"},
+		{"Go int has no JSON form", source, []Edit{{"/port", 9090}}, "", `pointer "/port": unsupported type int`},
+		{"NaN has no JSON form", source, []Edit{{"/ratio", math.NaN()}}, "", "unsupported number NaN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyEdits(tt.source, tt.edits)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
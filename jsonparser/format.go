@@ -0,0 +1,33 @@
+package jsonparser
+
+import "strings"
+
+// /**
+// * @brief Reformats JSON or JSONC source text in the canonical display layout.
+// *
+// * @details The text is parsed with comments allowed and with PreserveIntegers, so large integers keep
+// * every digit, and written like PrettyPrintCanonicalDisplay. With keepComments each comment is written
+// * on its own line above the value it documents; comments after a container's last member move above the
+// * container, and those after the document above the root. Without it the output is plain JSON.
+// *
+// * Repeated keys are refused with a *DuplicateKeyError rather than formatted, since only the last of
+// * each would survive and the others would be dropped without a word.
+// *
+// * @param src The source text.
+// * @param keepComments Whether comments are kept.
+// * @return The formatted text ending in '\n', or a parse or duplicate key error.
+// */
+func FormatSource(src string, keepComments bool) (string, error) {
+	doc, err := ParseDocument(src, ParseOptions{AllowComments: true, PreserveIntegers: true, DisallowDuplicateKeys: true})
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	p := &prettyPrinter{w: &sb, indent: "  ", inlineScalars: true, inlineWidth: canonicalWidth, sortKeys: true}
+	if keepComments {
+		p.comments = doc.Comments
+	}
+	p.writeComments("", "")
+	p.prettyPrint(doc.Value, 0)
+	return sb.String() + "\n", nil
+}
@@ -0,0 +1,51 @@
+package jsonparser
+
+import "testing"
+
+func TestFormatSourceKeepsIntegers(t *testing.T) {
+	got, err := FormatSource(`{"id": 9007199254740993, "min": -9223372036854775808}`, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"id\": 9007199254740993,\n  \"min\": -9223372036854775808\n}\n"
+	if got != want {
+		t.Errorf("FormatSource = %q, want %q", got, want)
+	}
+}
+
+func TestFormatSource(t *testing.T) {
+	tests := []struct {
+		name         string
+		src          string
+		keepComments bool
+		want         string
+		wantErr      string
+	}{
+		{"sorts and indents", `{"b":[1,2],"a":{"c":null}}`, false, "{\n  \"a\": {\n    \"c\": null\n  },\n  \"b\": [1, 2]\n}\n", ""},
+		{"keeps comments", "{\n  // the answer\n  \"a\": 42\n}", true, "{\n  // the answer\n  \"a\": 42\n}\n", ""},
+		{"strips comments", "{\n  // the answer\n  \"a\": 42\n}", false, "{\n  \"a\": 42\n}\n", ""},
+		{"refuses duplicate keys", `{"a": 1, "a": 2}`, false, "", "duplicate keys: /a"},
+		{"refuses nested duplicate keys", `{"x": [{"k": 1, "k": 1}]}`, false, "", "duplicate keys: /x/0/k"},
+		{"syntax error", `{"a": }`, false, "", "line 1, col 7"},
+		{"empty containers stay on one line", `{"a":{},"b":[]}`, false, "{\n  \"a\": {},\n  \"b\": []\n}\n", ""},
+		{"empty root object", "{\n\n}", false, "{}\n", ""},
+		{"empty root array", `[ ]`, false, "[]\n", ""},
+		{"empty containers under comments", "{\n  // none yet\n  \"items\": []\n}", true, "{\n  // none yet\n  \"items\": []\n}\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatSource(tt.src, tt.keepComments)
+			checkErr(t, err, tt.wantErr)
+			if got != tt.want {
+				t.Errorf("FormatSource = %q, want %q", got, tt.want)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			// Formatted output is already in canonical form.
+			if again, err := FormatSource(got, tt.keepComments); err != nil || again != got {
+				t.Errorf("formatting again gave %q, %v", again, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package jsonparser
+
+import "errors"
+
+// / defaultMaxIndexPaths is the number of paths Index records before it stops indexing.
+const defaultMaxIndexPaths = 1000000
+
+// / errIndexFull stops the walk that builds a PointerIndex once the path limit is reached.
+var errIndexFull = errors.New("index full")
+
+// / PointerIndex answers JSON Pointer lookups on one parsed tree from a precomputed table.
+// / The tree must not be modified while the index is in use.
+type PointerIndex struct {
+	root     interface{}
+	values   map[string]interface{} ///< every indexed value by its JSON Pointer
+	complete bool                   ///< whether every path in the tree was indexed
+}
+
+// /**
+// * @brief Indexes every path of a parsed tree for constant-time lookups.
+// *
+// * @details At most defaultMaxIndexPaths paths are recorded; see IndexWithLimit.
+// *
+// * @param root The parsed JSON value.
+// * @return The index.
+// */
+func Index(root interface{}) *PointerIndex {
+	return IndexWithLimit(root, defaultMaxIndexPaths)
+}
+
+// /**
+// * @brief Indexes the paths of a parsed tree, recording at most maxPaths of them.
+// *
+// * @details Paths are recorded in Walk order. Once the limit is reached the rest of the tree is left
+// * unindexed and lookups there fall back to Get, so results stay correct while memory stays bounded.
+// *
+// * @param root The parsed JSON value.
+// * @param maxPaths The most paths to record (0 = unlimited).
+// * @return The index.
+// */
+func IndexWithLimit(root interface{}, maxPaths int) *PointerIndex {
+	idx := &PointerIndex{root: root, values: make(map[string]interface{})}
+	err := Walk(root, func(pointer string, value interface{}) error {
+		if maxPaths > 0 && len(idx.values) >= maxPaths {
+			return errIndexFull
+		}
+		idx.values[pointer] = value
+		return nil
+	})
+	idx.complete = err == nil
+	return idx
+}
+
+// /**
+// * @brief Looks up the value a JSON Pointer refers to.
+// *
+// * @details Indexed pointers are answered from the table. Anything else, including malformed pointers
+// * and paths that don't exist, goes through Get, so results and errors always match Get's.
+// *
+// * @param pointer The RFC 6901 JSON Pointer ("" for the root).
+// * @return The referenced value or an error if the pointer doesn't resolve.
+// */
+func (idx *PointerIndex) Get(pointer string) (interface{}, error) {
+	if value, ok := idx.values[pointer]; ok {
+		return value, nil
+	}
+	return Get(idx.root, pointer)
+}
+
+// /**
+// * @brief Reports how many paths the index holds and whether that is all of them.
+// *
+// * @return The number of indexed paths, and false if the path limit cut indexing short.
+// */
+func (idx *PointerIndex) Len() (int, bool) {
+	return len(idx.values), idx.complete
+}
@@ -0,0 +1,91 @@
+package jsonparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPointerIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		maxPaths  int
+		wantLen   int
+		wantWhole bool
+	}{
+		{"scalar root", `42`, 0, 1, true},
+		{"nested document", `{"a": {"b": [1, {"c": null}]}, "x/y": {"~": "t"}, "e": []}`, 0, 9, true},
+		{"limit above the path count", `[1, [2, 3]]`, 10, 5, true},
+		{"limit equal to the path count", `[1, [2, 3]]`, 5, 5, true},
+		{"limit cuts indexing short", `{"a": [1, 2, 3], "b": {"c": true}}`, 3, 3, false},
+		{"wide document", wideDocument(200), 50, 50, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := mustParse(t, tt.input)
+			idx := IndexWithLimit(root, tt.maxPaths)
+			n, whole := idx.Len()
+			if n != tt.wantLen || whole != tt.wantWhole {
+				t.Errorf("Len = %d, %v, want %d, %v", n, whole, tt.wantLen, tt.wantWhole)
+			}
+
+			// Indexed or not, every path must resolve exactly as Get does.
+			err := Walk(root, func(pointer string, want interface{}) error {
+				got, err := idx.Get(pointer)
+				if err != nil {
+					t.Errorf("Get(%q): %v", pointer, err)
+				} else if !reflect.DeepEqual(got, want) {
+					t.Errorf("Get(%q) = %#v, want %#v", pointer, got, want)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, pointer := range []string{"/missing", "/a/9", "a", "/0/x", "/~2"} {
+				_, want := Get(root, pointer)
+				_, got := idx.Get(pointer)
+				if (got == nil) != (want == nil) || (got != nil && got.Error() != want.Error()) {
+					t.Errorf("Get(%q) error = %v, want %v", pointer, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexDefaultLimit(t *testing.T) {
+	root := mustParse(t, wideDocument(100))
+	n, whole := Index(root).Len()
+	if !whole {
+		t.Errorf("Index stopped after %d paths", n)
+	}
+}
+
+func BenchmarkPointerIndex(b *testing.B) {
+	root, err := ParseJSON(wideDocument(1000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var pointers []string
+	Walk(root, func(pointer string, _ interface{}) error {
+		pointers = append(pointers, pointer)
+		return nil
+	})
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := Get(root, pointers[i%len(pointers)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Index", func(b *testing.B) {
+		idx := Index(root)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := idx.Get(pointers[i%len(pointers)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package jsonparser
+
+// /**
+// * @brief Reports arrays whose elements don't all have the same JSON type.
+// *
+// * @details Mixing, say, numbers and strings in one array often points at a bug upstream. When
+// * nullCompatible is set, null elements are allowed alongside any single other type.
+// *
+// * @param root The JSON value to check.
+// * @param nullCompatible Whether null may appear in an otherwise homogeneous array.
+// * @return The JSON Pointers of the mixed-type arrays, in document order.
+// */
+func LintArrays(root interface{}, nullCompatible bool) []string {
+	var paths []string
+	Walk(root, func(pointer string, value interface{}) error {
+		arr, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		kind := ""
+		for _, elem := range arr {
+			k := valueKind(elem)
+			if k == "null" && nullCompatible {
+				continue
+			}
+			if kind == "" {
+				kind = k
+			} else if k != kind {
+				paths = append(paths, pointer)
+				break
+			}
+		}
+		return nil
+	})
+	return paths
+}
@@ -0,0 +1,36 @@
+package jsonparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintArrays(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		nullCompatible bool
+		want           []string
+	}{
+		{"homogeneous numbers", `[1, 2, 3]`, false, nil},
+		{"homogeneous objects", `[{"a": 1}, {"b": "x"}]`, false, nil},
+		{"empty array", `[]`, false, nil},
+		{"mixed root array", `[1, "2"]`, false, []string{""}},
+		{"mixed nested array", `{"a": {"b": [true, 1]}, "c": ["x", "y"]}`, false, []string{"/a/b"}},
+		{"key needing escapes", `{"a/b~c": [1, null]}`, false, []string{"/a~1b~0c"}},
+		{"arrays and objects differ", `[[1], {"a": 1}]`, false, []string{""}},
+		{"null is a type of its own", `[1, null]`, false, []string{""}},
+		{"null allowed when compatible", `[1, null, 2]`, true, nil},
+		{"only nulls when compatible", `[null, null]`, true, nil},
+		{"null doesn't hide a mix", `[1, null, "x"]`, true, []string{""}},
+		{"several in document order", `{"b": [1, "x"], "a": [[true, 0], 1]}`, false, []string{"/a", "/a/0", "/b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LintArrays(mustParse(t, tt.input), tt.nullCompatible)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LintArrays(%s) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package jsonparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// /**
+// * @brief Serializes a parsed JSON value to compact JSON text.
+// *
+// * @details Handles the types the parser produces (map[string]interface{}, []interface{}, string,
+// * float64, bool and nil) as well as json.Number and json.RawMessage. Object members are written in
+// * sorted key order so the output is deterministic. No whitespace is added.
+// *
+// * @param v The JSON value to serialize.
+// * @return The JSON text, or an error for an unsupported type, Infinity or NaN.
+// */
+func Marshal(v interface{}) ([]byte, error) {
+	var sb strings.Builder
+	p := &prettyPrinter{w: &sb, strict: true}
+	p.writeCompact(v, true)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []byte(sb.String()), nil
+}
+
+// /**
+// * @brief Serializes a parsed JSON value to indented JSON text, laid out like PrettyPrint.
+// *
+// * @details Object members are written in sorted key order, as Marshal does, so the output is
+// * deterministic.
+// *
+// * @param v The JSON value to serialize.
+// * @param indent The indentation unit written once per nesting level (e.g. "  " or "\t").
+// * @return The JSON text, or an error for an unsupported type, Infinity or NaN.
+// */
+func MarshalIndent(v interface{}, indent string) ([]byte, error) {
+	var sb strings.Builder
+	p := &prettyPrinter{w: &sb, indent: indent, sortKeys: true, strict: true}
+	p.prettyPrint(v, 0)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []byte(sb.String()), nil
+}
+
+// /**
+// * @brief Minifies JSON text without building the parsed tree.
+// *
+// * @details The input is tokenized and checked against the same grammar, and with the same errors, as
+// * ParseJSON, then written back without insignificant whitespace. Numbers and strings are copied as
+// * written: 1.50 stays 1.50, and string escapes, already valid, are kept rather than rewritten, so
+// * nothing is lost or reformatted. Object members keep their order, duplicates included.
+// *
+// * @param src The JSON text.
+// * @return The compact JSON text, or a parse error.
+// */
+func Compact(src []byte) ([]byte, error) {
+	s := string(src)
+	opts := ParseOptions{}
+	tokens, err := tokenizeWithOptions(s, &opts)
+	if err != nil {
+		if trailing := trailingText(s, &opts); trailing != nil {
+			return nil, trailing
+		}
+		return nil, err
+	}
+	ts := &TokenStream{tokens: tokens, opts: &opts, src: s}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	if err := compactValue(ts, &sb); err != nil {
+		return nil, err
+	}
+	if token := ts.Peek(); token.Type != TokenEOF {
+		return nil, trailingDataError(s, token.Offset)
+	}
+	return []byte(sb.String()), nil
+}
+
+// /**
+// * @brief Checks one value from the token stream and writes its tokens without whitespace.
+// *
+// * @details Follows parseValue, parseObject and parseArray step by step, so malformed input fails with
+// * the same messages, but writes source text instead of building values.
+// *
+// * @param ts The TokenStream to read from.
+// * @param sb The builder receiving the compact text.
+// * @return A parse error, or nil.
+// */
+func compactValue(ts *TokenStream, sb *strings.Builder) error {
+	token := ts.Next()
+	switch token.Type {
+	case TokenString, TokenNumber, TokenTrue, TokenFalse, TokenNull:
+		sb.WriteString(ts.src[token.Offset:token.End])
+		return nil
+	case TokenObjectStart, TokenArrayStart:
+	default:
+		return unexpectedToken("", token)
+	}
+
+	if ts.depth >= ts.opts.maxDepth() {
+		return fmt.Errorf("maximum nesting depth exceeded at line %d, col %d", token.Line, token.Column)
+	}
+	ts.depth++
+	defer func() { ts.depth-- }()
+	object := token.Type == TokenObjectStart
+	closer := TokenArrayEnd
+	if object {
+		closer = TokenObjectEnd
+	}
+	sb.WriteString(token.Type.String())
+	for first := true; ; first = false {
+		next := ts.Peek()
+		if next.Type == closer {
+			ts.Next()
+			sb.WriteString(closer.String())
+			return nil
+		}
+		if !first {
+			if next.Type != TokenComma {
+				return unexpectedToken(fmt.Sprintf("',' or '%s'", closer), next)
+			}
+			ts.Next()
+			sb.WriteByte(',')
+			if next = ts.Peek(); next.Type == closer {
+				return fmt.Errorf("trailing comma before '%s' at line %d, col %d", closer, next.Line, next.Column)
+			}
+		}
+		if next.Type == TokenComma {
+			if object {
+				return fmt.Errorf("missing member before ',' at line %d, col %d", next.Line, next.Column)
+			}
+			return fmt.Errorf("missing value before ',' at line %d, col %d", next.Line, next.Column)
+		}
+		if object {
+			if next.Type != TokenString {
+				return unexpectedToken("a string key", next)
+			}
+			ts.Next()
+			sb.WriteString(ts.src[next.Offset:next.End])
+			if colon := ts.Next(); colon.Type != TokenColon {
+				return unexpectedToken("':'", colon)
+			}
+			sb.WriteByte(':')
+		}
+		if err := compactValue(ts, sb); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package jsonparser
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalIndent(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		indent string
+		want   string
+	}{
+		{"empty array", []interface{}{}, "  ", "[]"},
+		{"empty object", map[string]interface{}{}, "  ", "{}"},
+		{"empty members", map[string]interface{}{"b": map[string]interface{}{}, "a": []interface{}{}}, "  ",
+			"{\n  \"a\": [],\n  \"b\": {}\n}"},
+		{"empty elements", []interface{}{[]interface{}{}, map[string]interface{}{}, 1.0}, "\t", "[\n\t[],\n\t{},\n\t1\n]"},
+		{"nested values", map[string]interface{}{"x": []interface{}{true, nil, "s"}, "y": map[string]interface{}{"z": 2.5}}, "  ",
+			"{\n  \"x\": [\n    true,\n    null,\n    \"s\"\n  ],\n  \"y\": {\n    \"z\": 2.5\n  }\n}"},
+		{"scalar", "hi", "  ", `"hi"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalIndent(tt.value, tt.indent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalIndent = %q, want %q", got, tt.want)
+			}
+			// encoding/json lays these values out the same way.
+			std, err := json.MarshalIndent(tt.value, "", tt.indent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(std) {
+				t.Errorf("MarshalIndent = %q, encoding/json gives %q", got, std)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package jsonparser
+
+import "golang.org/x/text/unicode/norm"
+
+// / TrailingDataMode decides what happens to input left over after the first JSON value.
+type TrailingDataMode int
+
+const (
+	TrailingDataError   TrailingDataMode = iota ///< fail with "extra tokens after value" (default)
+	TrailingDataIgnore                          ///< return the first value and ignore the rest, unscanned
+	TrailingDataCollect                         ///< parse the rest as further documents; several are returned as []interface{} (ParseAll always returns a slice)
+)
+
+// / ParseOptions selects optional, non-standard extensions to the JSON grammar.
+// / The zero value parses strict RFC 8259 JSON.
+type ParseOptions struct {
+	AllowUndefined bool ///< accept the JavaScript literal `undefined`, parsed as nil
+	AllowComments  bool ///< accept // line and /* block */ comments wherever whitespace may appear
+
+	AllowTrailingCommas bool ///< accept a comma after the last member of an object or element of an array
+	AllowSingleQuotes   bool ///< accept 'single-quoted' strings
+	AllowUnquotedKeys   bool ///< accept identifier object keys such as {name: 1} (ASCII letters, digits, _ and $)
+	AllowHexNumbers     bool ///< accept hexadecimal integers such as 0xFF
+	AllowOctalNumbers   bool ///< accept octal integers such as 0o17 (not part of JSON5)
+	AllowLooseDecimals  bool ///< accept a leading or trailing decimal point, as in .5 and 5.
+	AllowPlusSign       bool ///< accept an explicit leading + on numbers
+	AllowLeadingZeros   bool ///< accept redundant leading zeros such as 007, read as decimal (not part of JSON5)
+	AllowInfinityNaN    bool ///< accept Infinity, -Infinity and NaN
+
+	MaxObjectKeys int ///< reject objects with more members than this (0 = unlimited)
+	MaxArrayLen   int ///< reject arrays with more elements than this (0 = unlimited)
+	MaxDepth      int ///< reject objects and arrays nested deeper than this (0 = defaultMaxDepth)
+	MaxNumberLen  int ///< reject number literals longer than this many bytes (0 = unlimited)
+
+	OnTrailingData TrailingDataMode ///< what to do with data after the first value
+
+	DisallowDuplicateKeys bool                      ///< fail with a *DuplicateKeyError listing every key repeated within an object
+	OnDuplicateKey        func(key string, pos int) ///< called with the byte offset of every repeated key; the last value still wins
+
+	NormalizeUnicode bool      ///< normalize every string value and key to UnicodeForm
+	UnicodeForm      norm.Form ///< normalization form used by NormalizeUnicode (zero value is NFC)
+
+	TypedSlices      bool ///< return arrays of only numbers as []float64 ([]int64 for PreserveIntegers integers) and of only strings as []string
+	PreserveIntegers bool ///< return numbers without fraction or exponent that fit in int64 as int64 instead of float64
+}
+
+// / defaultMaxDepth is the nesting limit used when ParseOptions.MaxDepth is zero; deep enough for any
+// / real document, shallow enough that the recursive parser can't exhaust the stack.
+const defaultMaxDepth = 10000
+
+// /**
+// * @brief Returns the nesting limit in effect.
+// *
+// * @return MaxDepth, or defaultMaxDepth when it is not set.
+// */
+func (opts *ParseOptions) maxDepth() int {
+	if opts.MaxDepth > 0 {
+		return opts.MaxDepth
+	}
+	return defaultMaxDepth
+}
+
+// /**
+// * @brief Returns the lenient preset for importing sloppy, hand-written or JavaScript-sourced data.
+// *
+// * @return ParseOptions with every leniency extension enabled.
+// */
+func LenientOptions() ParseOptions {
+	opts := JSON5Options()
+	opts.AllowUndefined = true
+	opts.AllowOctalNumbers = true
+	opts.AllowLeadingZeros = true
+	return opts
+}
+
+// /**
+// * @brief Returns the options implementing the JSON5 grammar, as used by ParseJSON5.
+// *
+// * @return ParseOptions with the JSON5 extensions enabled.
+// */
+func JSON5Options() ParseOptions {
+	return ParseOptions{
+		AllowComments:       true,
+		AllowTrailingCommas: true,
+		AllowSingleQuotes:   true,
+		AllowUnquotedKeys:   true,
+		AllowHexNumbers:     true,
+		AllowLooseDecimals:  true,
+		AllowPlusSign:       true,
+		AllowInfinityNaN:    true,
+	}
+}
+
+// / PrettyOptions controls the layout produced by PrettyPrintWithOptions.
+// / The zero value matches PrettyPrint.
+type PrettyOptions struct {
+	Indent             string ///< indentation unit per nesting level (empty = two spaces)
+	InlineScalarArrays bool   ///< keep arrays whose elements are all scalars on one line, e.g. [1, 2, 3]
+	SortKeys           bool   ///< write object members in sorted key order, so equal values always print the same
+}
@@ -0,0 +1,2221 @@
+// Package jsonparser tokenizes, parses, queries and formats JSON. The jsonparser command and its
+// terminal viewer are built on it.
+package jsonparser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// / TokenType defines the possible types of tokens in JSON.
+type TokenType int
+
+// / canonicalWidth is the line width PrettyPrintCanonicalDisplay keeps inline scalar arrays within.
+const canonicalWidth = 80
+
+// / maxSafeInteger is the largest integer a float64 holds exactly (2^53).
+const maxSafeInteger = 1 << 53
+
+// ! defined grammer
+const (
+	TokenObjectStart TokenType = iota ///< {
+	TokenObjectEnd                    ///< }
+	TokenArrayStart                   ///< [
+	TokenArrayEnd                     ///< ]
+	TokenColon                        ///< :
+	TokenComma                        ///< ,
+	TokenString                       ///< string literal
+	TokenNumber                       ///< number
+	TokenTrue                         ///< true
+	TokenFalse                        ///< false
+	TokenNull                         ///< null
+	TokenEOF                          ///< end of input
+	TokenError                        ///< invalid input skipped by TokenizeRecover
+	TokenUndefined                    ///< undefined (lenient extension)
+	TokenComment                      ///< // or /* */ comment (lenient extension)
+	TokenIdentifier                   ///< unquoted object key (JSON5 extension)
+)
+
+// /**
+// * @brief Names the token type the way error messages show it.
+// *
+// * @return The punctuation itself for structural tokens, e.g. "{", and a word such as "string" otherwise.
+// */
+func (t TokenType) String() string {
+	switch t {
+	case TokenObjectStart:
+		return "{"
+	case TokenObjectEnd:
+		return "}"
+	case TokenArrayStart:
+		return "["
+	case TokenArrayEnd:
+		return "]"
+	case TokenColon:
+		return ":"
+	case TokenComma:
+		return ","
+	case TokenString:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenTrue:
+		return "true"
+	case TokenFalse:
+		return "false"
+	case TokenNull:
+		return "null"
+	case TokenEOF:
+		return "end of input"
+	case TokenError:
+		return "invalid token"
+	case TokenUndefined:
+		return "undefined"
+	case TokenComment:
+		return "comment"
+	case TokenIdentifier:
+		return "identifier"
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
+// / Token represents a single token with its type and optional value.
+// / The Value field is a string for TokenString, a float64 for TokenNumber (an int64 for integers under
+// / ParseOptions.PreserveIntegers), a *SyntaxError for
+// / TokenError, the comment text for TokenComment, the name for TokenIdentifier, and nil otherwise.
+type Token struct {
+	Type   TokenType
+	Value  interface{}
+	Offset int ///< byte offset of the token's first character
+	End    int ///< byte offset just past the token's last character
+	Line   int ///< 1-based line of the token's first character
+	Column int ///< 1-based column, in characters, of the token's first character
+}
+
+// /**
+// * @brief Describes the token for error messages, with its value where it has one.
+// *
+// * @return For example `string ("hello")`, `number (12)`, `identifier (name)` or `}`.
+// */
+func (t Token) String() string {
+	switch t.Type {
+	case TokenString:
+		if s, ok := t.Value.(string); ok {
+			return fmt.Sprintf("string (%s)", escapeString(s))
+		}
+	case TokenNumber:
+		switch n := t.Value.(type) {
+		case float64:
+			return fmt.Sprintf("number (%s)", FormatNumber(n))
+		case int64:
+			return fmt.Sprintf("number (%d)", n)
+		}
+	case TokenIdentifier:
+		if s, ok := t.Value.(string); ok {
+			return fmt.Sprintf("identifier (%s)", s)
+		}
+	case TokenError:
+		if err, ok := t.Value.(*SyntaxError); ok {
+			return fmt.Sprintf("invalid token (%s)", err.Msg)
+		}
+	}
+	return t.Type.String()
+}
+
+// /**
+// * @brief Formats an error about a token that doesn't belong where it was found.
+// *
+// * @param what What the parser wanted instead, e.g. "',' or '}'", or "" for a bare "unexpected token".
+// * @param token The offending token.
+// * @return The error, e.g. "expected ':' but found 'number (1)' at line 3, col 9".
+// */
+func unexpectedToken(what string, token Token) error {
+	if what == "" {
+		return fmt.Errorf("unexpected token '%s' at line %d, col %d", token, token.Line, token.Column)
+	}
+	return fmt.Errorf("expected %s but found '%s' at line %d, col %d", what, token, token.Line, token.Column)
+}
+
+// / SyntaxError describes a problem found while scanning JSON input.
+type SyntaxError struct {
+	Msg    string ///< description of the problem
+	Offset int    ///< byte offset where the problem starts
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Msg
+}
+
+// / DuplicateKeyError lists every repeated object key found while parsing with DisallowDuplicateKeys.
+type DuplicateKeyError struct {
+	Paths []string ///< JSON Pointers of the repeated keys, in document order
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return "duplicate keys: " + strings.Join(e.Paths, ", ")
+}
+
+// /**
+// * @brief Renders the error as a multi-line diagnostic for showing to users.
+// *
+// * @details The block starts with the message, which names the line and column, followed by the
+// * offending line of source with a caret under the error position, and the line before and after it for
+// * context. Tabs in the source are kept in the caret line so the caret stays aligned.
+// *
+// * @param source The JSON text the error was found in.
+// * @return The rendered diagnostic, ending in a newline.
+// */
+func (e *SyntaxError) Render(source string) string {
+	offset := e.Offset
+	if offset > len(source) {
+		offset = len(source)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	lines := strings.Split(source, "\n")
+	lineNo := strings.Count(source[:offset], "\n")
+	lineStart := strings.LastIndexByte(source[:offset], '\n') + 1
+	prefix := source[lineStart:offset]
+
+	var sb strings.Builder
+	sb.WriteString(e.Msg + "\n")
+	width := len(strconv.Itoa(min(lineNo+2, len(lines))))
+	for i := max(lineNo-1, 0); i <= lineNo+1 && i < len(lines); i++ {
+		fmt.Fprintf(&sb, "%*d | %s\n", width, i+1, strings.TrimRight(lines[i], "\r"))
+		if i == lineNo {
+			/// Pad with the prefix's own tabs so the caret lines up under tab-indented source.
+			pad := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, prefix)
+			fmt.Fprintf(&sb, "%*s | %s^\n", width, "", pad)
+		}
+	}
+	return sb.String()
+}
+
+// / lineCounter follows an offset moving forward through a string, keeping its line and column.
+type lineCounter struct {
+	src  string
+	pos  int ///< offset the line and column refer to
+	line int ///< 1-based line of pos
+	col  int ///< 1-based column of pos, counted in characters
+}
+
+// /**
+// * @brief Moves the counter forward to offset to, counting the newlines and characters passed.
+// *
+// * @param to The new offset (not before the current one).
+// */
+func (c *lineCounter) advance(to int) {
+	for ; c.pos < to; c.pos++ {
+		switch b := c.src[c.pos]; {
+		case b == '\n':
+			c.line++
+			c.col = 1
+		case !utf8.RuneStart(b):
+			/// Continuation bytes belong to the character already counted.
+		default:
+			c.col++
+		}
+	}
+}
+
+// /**
+// * @brief Creates a counter at the start of src.
+// *
+// * @param src The string to follow.
+// * @return The counter at line 1, column 1.
+// */
+func newLineCounter(src string) *lineCounter {
+	return &lineCounter{src: src, line: 1, col: 1}
+}
+
+// /**
+// * @brief Describes where an offset is in human terms, for error messages.
+// *
+// * @details The count continues from the counter's own position without moving it, so an error inside
+// * the token being scanned costs only the length of that token. The tokenizers keep their counter at
+// * the token start; an offset before it is counted from the start of the source.
+// *
+// * @param index The byte offset.
+// * @return The position as "line L, col C".
+// */
+func (c *lineCounter) position(index int) string {
+	at := *c
+	if index < at.pos {
+		at = *newLineCounter(c.src)
+	}
+	at.advance(min(index, len(at.src)))
+	return fmt.Sprintf("line %d, col %d", at.line, at.col)
+}
+
+// /**
+// * @brief Tokenizes a JSON string into a slice of tokens.
+// *
+// * @details This function iterates through the input JSON string, identifying and categorizing tokens.
+// * Whitespace characters (' ', '\t', '\n', '\r') are skipped as they are insignificant outside strings;
+// * everything else is handed to scanToken. The function appends a TokenEOF at the end to signify the
+// * end of input. Every token carries its byte offsets and line and column, so the result is enough to
+// * drive a linter or syntax highlighter, or a custom parser through NewTokenStream.
+// *
+// * @param jsonStr The JSON string to tokenize.
+// * @return A slice of tokens and an error (nil if successful).
+// */
+func Tokenize(jsonStr string) ([]Token, error) {
+	return tokenizeWithOptions(jsonStr, &ParseOptions{})
+}
+
+// /**
+// * @brief Tokenizes a JSON string, accepting the grammar extensions enabled in opts.
+// *
+// * @details Comments are returned as TokenComment tokens when opts.AllowComments is set.
+// *
+// * @param jsonStr The JSON string to tokenize.
+// * @param opts The parse options selecting grammar extensions.
+// * @return A slice of tokens and an error (nil if successful).
+// */
+func TokenizeWithOptions(jsonStr string, opts ParseOptions) ([]Token, error) {
+	return tokenizeWithOptions(jsonStr, &opts)
+}
+
+// /**
+// * @brief Implements TokenizeWithOptions, taking opts by pointer the way the parser passes them.
+// *
+// * @param jsonStr The JSON string to tokenize.
+// * @param opts The parse options selecting grammar extensions.
+// * @return A slice of tokens and an error (nil if successful).
+// */
+func tokenizeWithOptions(jsonStr string, opts *ParseOptions) ([]Token, error) {
+	var tokens []Token
+	lc := newLineCounter(jsonStr)
+	index := 0
+	for {
+		index = skipWhitespace(jsonStr, index)
+		if index >= len(jsonStr) {
+			break
+		}
+		lc.advance(index)
+		token, newIndex, err := scanToken(lc, index, opts)
+		if err != nil {
+			return nil, err
+		}
+		token.Line, token.Column, token.End = lc.line, lc.col, newIndex
+		tokens = append(tokens, token)
+		index = newIndex
+	}
+	/// Append end-of-file token.
+	lc.advance(index)
+	tokens = append(tokens, Token{Type: TokenEOF, Offset: index, End: index, Line: lc.line, Column: lc.col})
+	return tokens, nil
+}
+
+// /**
+// * @brief Tokenizes a JSON string, recovering from lexical errors instead of stopping at the first one.
+// *
+// * @details Every invalid token is recorded as a SyntaxError and replaced in the stream by a TokenError
+// * whose Value is that *SyntaxError. Scanning then resynchronizes at the next whitespace or structural
+// * character (or the next line, for a broken string) so later problems are reported in the same pass.
+// *
+// * @param jsonStr The JSON string to tokenize.
+// * @return The best-effort token stream (ending in TokenEOF) and all syntax errors found.
+// */
+func TokenizeRecover(jsonStr string) ([]Token, []SyntaxError) {
+	return tokenizeRecover(jsonStr, &ParseOptions{})
+}
+
+// /**
+// * @brief Tokenizes like TokenizeRecover, accepting the grammar extensions enabled in opts.
+// *
+// * @param jsonStr The JSON string to tokenize.
+// * @param opts The parse options selecting grammar extensions.
+// * @return The best-effort token stream (ending in TokenEOF) and all syntax errors found.
+// */
+func TokenizeRecoverWithOptions(jsonStr string, opts ParseOptions) ([]Token, []SyntaxError) {
+	return tokenizeRecover(jsonStr, &opts)
+}
+
+// /**
+// * @brief Implements TokenizeRecoverWithOptions, taking opts by pointer the way the parser passes them.
+// *
+// * @param jsonStr The JSON string to tokenize.
+// * @param opts The parse options selecting grammar extensions.
+// * @return The best-effort token stream (ending in TokenEOF) and all syntax errors found.
+// */
+func tokenizeRecover(jsonStr string, opts *ParseOptions) ([]Token, []SyntaxError) {
+	var tokens []Token
+	var errs []SyntaxError
+	lc := newLineCounter(jsonStr)
+	index := 0
+	for {
+		index = skipWhitespace(jsonStr, index)
+		if index >= len(jsonStr) {
+			break
+		}
+		lc.advance(index)
+		token, newIndex, err := scanToken(lc, index, opts)
+		if err == nil {
+			token.Line, token.Column, token.End = lc.line, lc.col, newIndex
+			tokens = append(tokens, token)
+			index = newIndex
+			continue
+		}
+		synErr := SyntaxError{Msg: err.Error(), Offset: index}
+		errs = append(errs, synErr)
+		end := resync(jsonStr, index)
+		tokens = append(tokens, Token{Type: TokenError, Value: &synErr, Offset: index, End: end, Line: lc.line, Column: lc.col})
+		index = end
+	}
+	lc.advance(index)
+	tokens = append(tokens, Token{Type: TokenEOF, Offset: index, End: index, Line: lc.line, Column: lc.col})
+	return tokens, errs
+}
+
+// /**
+// * @brief Finds where scanning can safely resume after an invalid token.
+// *
+// * @details A broken string skips to the end of its line, since strings can't span lines. Anything
+// * else skips to the next whitespace or structural character.
+// *
+// * @param jsonStr The JSON string being scanned.
+// * @param index The offset of the invalid token.
+// * @return The offset to resume scanning from (always past index).
+// */
+func resync(jsonStr string, index int) int {
+	if jsonStr[index] == '"' {
+		if nl := strings.IndexByte(jsonStr[index:], '\n'); nl >= 0 {
+			return index + nl + 1
+		}
+		return len(jsonStr)
+	}
+	index++
+	for index < len(jsonStr) && !strings.ContainsRune(" \t\n\r{}[]:,\"", rune(jsonStr[index])) {
+		index++
+	}
+	return index
+}
+
+// /**
+// * @brief Skips insignificant whitespace.
+// *
+// * @param jsonStr The JSON string being scanned.
+// * @param index The offset to start from.
+// * @return The offset of the next non-whitespace byte (or len(jsonStr)).
+// */
+func skipWhitespace(jsonStr string, index int) int {
+	/// Skip whitespace characters: space (' '), tab ('\t'), newline ('\n'), carriage return ('\r')
+	for index < len(jsonStr) {
+		char := jsonStr[index]
+		if char != ' ' && char != '\t' && char != '\n' && char != '\r' {
+			break
+		}
+		index++
+	}
+	return index
+}
+
+// /**
+// * @brief Scans the single token that starts at index.
+// *
+// * @details It handles:
+// * - Structural characters ('{', '}', '[', ']', ':', ',') by mapping them to their respective token types.
+// * - String literals (starting with a double quote, or a single quote when opts.AllowSingleQuotes is set)
+// *   by delegating to parseQuoted, then Unicode-normalizing them when opts.NormalizeUnicode is set.
+// * - Numbers (starting with digits or '-', and with '+', '.', 'I' or 'N' when the matching extension is
+// *   enabled) by delegating to parseNumber.
+// * - Identifiers when opts.AllowUnquotedKeys is set, as TokenIdentifier for use as object keys.
+// * - Literal 'true' (starting with 't') by checking the full word and creating a TokenTrue token.
+// * - Literal 'false' (starting with 'f') by checking the full word and creating a TokenFalse token.
+// * - Literal 'null' (starting with 'n') by checking the full word and creating a TokenNull token.
+// * - Literal 'undefined' (starting with 'u') when opts.AllowUndefined is set.
+// * - Comments (starting with '/') when opts.AllowComments is set, by delegating to scanComment.
+// * - Unexpected characters by returning an error.
+// *
+// * @param lc The source being scanned, with its line and column counted up to the token start.
+// * @param index The offset of the token's first byte (must not be whitespace).
+// * @param opts The parse options selecting grammar extensions.
+// * @return The token, the offset just past it, and an error (nil if successful).
+// */
+func scanToken(lc *lineCounter, index int, opts *ParseOptions) (Token, int, error) {
+	jsonStr := lc.src
+	char := jsonStr[index]
+	if opts.AllowUnquotedKeys && isIdentStart(char) {
+		/// Any word that isn't a literal is an unquoted key.
+		end := index + 1
+		for end < len(jsonStr) && (isIdentStart(jsonStr[end]) || '0' <= jsonStr[end] && jsonStr[end] <= '9') {
+			end++
+		}
+		if word := jsonStr[index:end]; !isLiteral(word, opts) {
+			return Token{Type: TokenIdentifier, Value: word, Offset: index}, end, nil
+		}
+	}
+	switch char {
+	case '{':
+		/// Token for object start.
+		return Token{Type: TokenObjectStart, Offset: index}, index + 1, nil
+	case '}':
+		/// Token for object end.
+		return Token{Type: TokenObjectEnd, Offset: index}, index + 1, nil
+	case '[':
+		/// Token for array start.
+		return Token{Type: TokenArrayStart, Offset: index}, index + 1, nil
+	case ']':
+		/// Token for array end.
+		return Token{Type: TokenArrayEnd, Offset: index}, index + 1, nil
+	case ':':
+		/// Token for colon.
+		return Token{Type: TokenColon, Offset: index}, index + 1, nil
+	case ',':
+		/// Token for comma.
+		return Token{Type: TokenComma, Offset: index}, index + 1, nil
+	case '"', '\'':
+		/// Parse string literal.
+		if char == '\'' && !opts.AllowSingleQuotes {
+			break
+		}
+		str, newIndex, err := parseQuoted(lc, index, char)
+		if err != nil {
+			return Token{}, index, err
+		}
+		if opts.NormalizeUnicode {
+			/// Bring canonically equivalent strings to one composition.
+			str = opts.UnicodeForm.String(str)
+		}
+		return Token{Type: TokenString, Value: str, Offset: index}, newIndex, nil
+	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '-', '+', '.', 'I', 'N':
+		/// Parse number, with the sign, point and word forms only under their extensions.
+		if char == '+' && !opts.AllowPlusSign || char == '.' && !opts.AllowLooseDecimals ||
+			(char == 'I' || char == 'N') && !opts.AllowInfinityNaN {
+			break
+		}
+		num, newIndex, err := parseNumber(lc, index, opts)
+		if err != nil {
+			return Token{}, index, err
+		}
+		if opts.PreserveIntegers {
+			if n, ok := parseInteger(jsonStr[index:newIndex]); ok {
+				return Token{Type: TokenNumber, Value: n, Offset: index}, newIndex, nil
+			}
+		}
+		return Token{Type: TokenNumber, Value: num, Offset: index}, newIndex, nil
+	case 't':
+		/// Handle literal 'true'.
+		if index+4 <= len(jsonStr) && jsonStr[index:index+4] == "true" {
+			return Token{Type: TokenTrue, Offset: index}, index + 4, nil
+		}
+		return Token{}, index, fmt.Errorf("invalid token at %s, expected 'true'", lc.position(index))
+	case 'f':
+		/// Handle literal 'false'.
+		if index+5 <= len(jsonStr) && jsonStr[index:index+5] == "false" {
+			return Token{Type: TokenFalse, Offset: index}, index + 5, nil
+		}
+		return Token{}, index, fmt.Errorf("invalid token at %s, expected 'false'", lc.position(index))
+	case 'n':
+		/// Handle literal 'null'.
+		if index+4 <= len(jsonStr) && jsonStr[index:index+4] == "null" {
+			return Token{Type: TokenNull, Offset: index}, index + 4, nil
+		}
+		return Token{}, index, fmt.Errorf("invalid token at %s, expected 'null'", lc.position(index))
+	case 'u':
+		/// Handle literal 'undefined' (lenient extension).
+		if opts.AllowUndefined && index+9 <= len(jsonStr) && jsonStr[index:index+9] == "undefined" {
+			return Token{Type: TokenUndefined, Offset: index}, index + 9, nil
+		}
+	case '/':
+		/// Handle comments (lenient extension).
+		if opts.AllowComments {
+			return scanComment(lc, index)
+		}
+	}
+	return Token{}, index, fmt.Errorf("unexpected character at %s: %c", lc.position(index), char)
+}
+
+// /**
+// * @brief Reports whether c can start an unquoted key: an ASCII letter, '_' or '$'.
+// */
+func isIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '$'
+}
+
+// /**
+// * @brief Reports whether word is a literal (true, false, null, or an enabled extension literal).
+// *
+// * @param word The identifier-like word.
+// * @param opts The parse options selecting grammar extensions.
+// * @return True if word is scanned as a value rather than as an unquoted key.
+// */
+func isLiteral(word string, opts *ParseOptions) bool {
+	switch word {
+	case "true", "false", "null":
+		return true
+	case "undefined":
+		return opts.AllowUndefined
+	case "Infinity", "NaN":
+		return opts.AllowInfinityNaN
+	}
+	return false
+}
+
+// /**
+// * @brief Scans a '//' line comment or a '/* */' block comment.
+// *
+// * @details The token's Value is the comment text without its delimiters and surrounding whitespace.
+// * A line comment runs to the end of its line; the newline is left for skipWhitespace.
+// *
+// * @param lc The source being scanned, with its line and column counted up to the token start.
+// * @param index The offset of the comment's leading '/'.
+// * @return The TokenComment, the offset just past the comment, and an error for a malformed comment.
+// */
+func scanComment(lc *lineCounter, index int) (Token, int, error) {
+	jsonStr := lc.src
+	if strings.HasPrefix(jsonStr[index:], "//") {
+		end := strings.IndexByte(jsonStr[index:], '\n')
+		if end < 0 {
+			end = len(jsonStr) - index
+		}
+		text := strings.TrimSpace(jsonStr[index+2 : index+end])
+		return Token{Type: TokenComment, Value: text, Offset: index}, index + end, nil
+	}
+	if strings.HasPrefix(jsonStr[index:], "/*") {
+		end := strings.Index(jsonStr[index+2:], "*/")
+		if end < 0 {
+			return Token{}, index, fmt.Errorf("unterminated comment at %s", lc.position(index))
+		}
+		text := strings.TrimSpace(jsonStr[index+2 : index+2+end])
+		return Token{Type: TokenComment, Value: text, Offset: index}, index + 2 + end + 2, nil
+	}
+	return Token{}, index, fmt.Errorf("unexpected character at %s: /", lc.position(index))
+}
+
+// /**
+// * @brief Parses a JSON string literal starting at the given index.
+// *
+// * @details This function processes a string literal starting with '"', handling:
+// * - Normal characters by adding them to the result.
+// * - Escape sequences (e.g., '\t', '\n', '\r', '\f', '\b') by interpreting them correctly.
+// * - Unicode escapes ('\uXXXX') by converting the hexadecimal code to a rune. A high surrogate
+// *   (D800-DBFF) must be followed by a low surrogate escape (DC00-DFFF) and the pair becomes one rune;
+// *   an unpaired surrogate is an error.
+// * - The closing quote ('"') to terminate the string.
+// * It returns an error if the string is unterminated or contains invalid escape sequences.
+// *
+// * @param jsonStr The JSON string being parsed.
+// * @param index The current index in the string (should point to the opening quote).
+// * @return The parsed string, the new index after the closing quote, and any error.
+// */
+func parseString(jsonStr string, index int) (string, int, error) {
+	return parseQuoted(newLineCounter(jsonStr), index, '"')
+}
+
+// /**
+// * @brief Parses a string literal delimited by the given quote character.
+// *
+// * @details For single-quoted strings, an escaped single quote is also accepted and a double quote
+// * needs no escaping.
+// *
+// * @param lc The source being scanned, with its line and column counted up to the token start.
+// * @param index The current index in the string (should point to the opening quote).
+// * @param quote The quote character: a double or single quote.
+// * @return The parsed string, the new index after the closing quote, and any error.
+// */
+func parseQuoted(lc *lineCounter, index int, quote byte) (string, int, error) {
+	jsonStr := lc.src
+	if jsonStr[index] != quote {
+		return "", index, fmt.Errorf("expected quote at %s", lc.position(index))
+	}
+	start := index
+	index++ // Skip opening quote
+
+	var sb strings.Builder
+
+	for index < len(jsonStr) {
+		char := jsonStr[index]
+
+		if char == quote {
+			/// End of string literal.
+			return sb.String(), index + 1, nil
+		}
+		if char == '\\' {
+			index++
+			if index >= len(jsonStr) {
+				return "", index, fmt.Errorf("unterminated string starting at %s", lc.position(start))
+			}
+
+			switch esc := jsonStr[index]; esc {
+			case '"', '\\', '/', quote:
+				/// Append the escaped character.
+				sb.WriteByte(esc)
+				index++
+			case 'b':
+				sb.WriteByte('\b')
+				index++
+			case 'f':
+				sb.WriteByte('\f')
+				index++
+			case 'n':
+				sb.WriteByte('\n')
+				index++
+			case 'r':
+				sb.WriteByte('\r')
+				index++
+			case 't':
+				sb.WriteByte('\t')
+				index++
+			case 'u':
+				/// Handle Unicode escape sequence '\uXXXX'.
+				if index+4 >= len(jsonStr) {
+					return "", index, fmt.Errorf("invalid unicode escape at %s", lc.position(index))
+				}
+				hex := jsonStr[index+1 : index+5]
+				r, err := strconv.ParseUint(hex, 16, 32)
+				if err != nil {
+					return "", index, fmt.Errorf("invalid unicode escape at %s", lc.position(index))
+				}
+				escape := index - 1
+				index += 5
+				/// Characters beyond the BMP arrive as a high surrogate escape followed by a low one.
+				if 0xDC00 <= r && r <= 0xDFFF {
+					return "", index, fmt.Errorf("unpaired low surrogate \\u%s at %s", hex, lc.position(escape))
+				}
+				if 0xD800 <= r && r <= 0xDBFF {
+					lo := uint64(0)
+					if index+6 <= len(jsonStr) && jsonStr[index:index+2] == "\\u" {
+						lo, _ = strconv.ParseUint(jsonStr[index+2:index+6], 16, 32)
+					}
+					if lo < 0xDC00 || lo > 0xDFFF {
+						return "", index, fmt.Errorf("high surrogate \\u%s at %s must be followed by a \\uDC00-\\uDFFF low surrogate", hex, lc.position(escape))
+					}
+					sb.WriteRune(utf16.DecodeRune(rune(r), rune(lo)))
+					index += 6
+					continue
+				}
+				sb.WriteRune(rune(r))
+			default:
+				return "", index, fmt.Errorf("invalid escape character at %s", lc.position(index))
+			}
+		} else {
+			/// Append regular character.
+			sb.WriteByte(char)
+			index++
+		}
+	}
+	return "", index, fmt.Errorf("unterminated string starting at %s", lc.position(start))
+}
+
+// /**
+// * @brief Parses a JSON number starting at the given index.
+// *
+// * @details This function parses numbers, which may include:
+// * - Integers (e.g., "123").
+// * - Floating-point numbers (e.g., "12.34").
+// * - Scientific notation (e.g., "1.23e-4").
+// * The digits are validated against the JSON number grammar by scanDecimal before the text is converted
+// * to a float64, so malformed numbers such as 01, 1.2.3 or 1e are rejected with the part at fault.
+// *
+// * With the matching extensions in opts it also accepts a leading '+', Infinity and NaN (optionally
+// * signed), hexadecimal integers such as 0xFF, octal integers such as 0o17, a leading or trailing
+// * decimal point (.5, 5.) and redundant leading zeros (007, read as decimal 7). Hex and octal values are
+// * stored as float64 like every other number. AllowPlusSign, AllowLooseDecimals and AllowLeadingZeros
+// * together accept the sloppy numbers common in hand-made data: +5, 05 and 5. all read as 5.
+// *
+// * When opts.MaxNumberLen is set, a longer literal is rejected after looking at just that many bytes,
+// * so a huge run of digits costs no more than a short one.
+// *
+// * @param lc The source being scanned, with its line and column counted up to the token start.
+// * @param index The current index in the string (should point to the start of the number).
+// * @param opts The parse options selecting grammar extensions.
+// * @return The parsed number as a float64, the new index, and any error.
+// */
+func parseNumber(lc *lineCounter, index int, opts *ParseOptions) (float64, int, error) {
+	jsonStr := lc.src
+	start := index
+	if limit := opts.MaxNumberLen; limit > 0 && numberRunExceeds(jsonStr, start, limit) {
+		return 0, start, fmt.Errorf("number literal at %s is longer than %d bytes", lc.position(start), limit)
+	}
+	sign := 1.0
+	if c := jsonStr[index]; c == '+' || c == '-' {
+		if c == '+' && !opts.AllowPlusSign {
+			return 0, start, fmt.Errorf("unexpected '+' at %s", lc.position(start))
+		}
+		if c == '-' {
+			sign = -1
+		}
+		index++
+	}
+	rest := jsonStr[index:]
+	switch {
+	case opts.AllowInfinityNaN && strings.HasPrefix(rest, "Infinity"):
+		return sign * math.Inf(1), index + len("Infinity"), nil
+	case opts.AllowInfinityNaN && strings.HasPrefix(rest, "NaN"):
+		return math.NaN(), index + len("NaN"), nil
+	case opts.AllowHexNumbers && (strings.HasPrefix(rest, "0x") || strings.HasPrefix(rest, "0X")):
+		end := index + 2
+		for end < len(jsonStr) && strings.IndexByte("0123456789abcdefABCDEF", jsonStr[end]) >= 0 {
+			end++
+		}
+		v, err := strconv.ParseUint(jsonStr[index+2:end], 16, 64)
+		if err != nil {
+			return 0, start, fmt.Errorf("invalid hex number %q at %s", jsonStr[start:end], lc.position(start))
+		}
+		return sign * float64(v), end, nil
+	case opts.AllowOctalNumbers && (strings.HasPrefix(rest, "0o") || strings.HasPrefix(rest, "0O")):
+		end := index + 2
+		for end < len(jsonStr) && '0' <= jsonStr[end] && jsonStr[end] <= '7' {
+			end++
+		}
+		v, err := strconv.ParseUint(jsonStr[index+2:end], 8, 64)
+		if err != nil {
+			return 0, start, fmt.Errorf("invalid octal number %q at %s", jsonStr[start:end], lc.position(start))
+		}
+		return sign * float64(v), end, nil
+	}
+	end, err := scanDecimal(jsonStr, index, opts)
+	if err != nil {
+		return 0, start, fmt.Errorf("invalid number %q at %s: %v", numberText(jsonStr, start), lc.position(start), err)
+	}
+	num, err := strconv.ParseFloat(jsonStr[start:end], 64)
+	if err != nil {
+		return 0, start, fmt.Errorf("invalid number %q at %s", jsonStr[start:end], lc.position(start))
+	}
+	return num, end, nil
+}
+
+// /**
+// * @brief Reads a number literal as an int64, for ParseOptions.PreserveIntegers.
+// *
+// * @details Only plain decimal integers qualify: an optional sign followed by digits. Literals with a
+// * fraction or exponent, hex and octal forms, Infinity, NaN and -0 are left as float64, as are integers
+// * outside the int64 range.
+// *
+// * @param text The literal, already validated by parseNumber.
+// * @return The integer, and whether text was one that fits in an int64.
+// */
+func parseInteger(text string) (int64, bool) {
+	digits := strings.TrimLeft(text, "+-")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" || strings.HasPrefix(text, "-") && strings.Trim(digits, "0") == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	return n, err == nil
+}
+
+// /**
+// * @brief Scans the unsigned part of a decimal number, validating it against the RFC 8259 grammar.
+// *
+// * @details The grammar is int [frac] [exp], where int is 0 or a digit sequence without a leading zero,
+// * frac is '.' followed by digits, and exp is 'e' or 'E', an optional sign and digits. With
+// * opts.AllowLooseDecimals the digits on one side of the '.' may be missing (.5, 5.). A number running
+// * straight into another number character or a letter (1.2.3, 0x10, 12abc) is rejected as a whole.
+// *
+// * @param jsonStr The JSON string being parsed.
+// * @param index The offset just after any sign.
+// * @param opts The parse options selecting grammar extensions.
+// * @return The offset just past the number, or an error naming the malformed part.
+// */
+func scanDecimal(jsonStr string, index int, opts *ParseOptions) (int, error) {
+	digits := func(i int) int {
+		for i < len(jsonStr) && '0' <= jsonStr[i] && jsonStr[i] <= '9' {
+			i++
+		}
+		return i
+	}
+
+	/// Integer part.
+	intStart, intEnd := index, digits(index)
+	switch {
+	case intEnd == index && !(opts.AllowLooseDecimals && index < len(jsonStr) && jsonStr[index] == '.'):
+		return 0, fmt.Errorf("missing digits in integer part")
+	case intEnd-index > 1 && jsonStr[index] == '0' && !opts.AllowLeadingZeros:
+		return 0, fmt.Errorf("leading zero in integer part")
+	}
+	index = intEnd
+
+	/// Fraction.
+	if index < len(jsonStr) && jsonStr[index] == '.' {
+		fracEnd := digits(index + 1)
+		if fracEnd == index+1 && (!opts.AllowLooseDecimals || intEnd == intStart) {
+			return 0, fmt.Errorf("missing digits after '.'")
+		}
+		index = fracEnd
+	}
+
+	/// Exponent.
+	if index < len(jsonStr) && (jsonStr[index] == 'e' || jsonStr[index] == 'E') {
+		index++
+		if index < len(jsonStr) && (jsonStr[index] == '+' || jsonStr[index] == '-') {
+			index++
+		}
+		expEnd := digits(index)
+		if expEnd == index {
+			return 0, fmt.Errorf("missing digits in exponent")
+		}
+		index = expEnd
+	}
+
+	if index < len(jsonStr) {
+		if c := jsonStr[index]; strings.IndexByte(".eE+-", c) >= 0 || isIdentStart(c) {
+			return 0, fmt.Errorf("unexpected %q after number", c)
+		}
+	}
+	return index, nil
+}
+
+// /**
+// * @brief Checks that text is exactly one number as RFC 8259 writes it.
+// *
+// * @details Unlike strconv.ParseFloat, forms JSON has no place for are rejected: NaN, Inf, Infinity, hex
+// * floats such as 0x1p-2, a leading '+', leading zeros, underscores, and surrounding whitespace.
+// *
+// * @param text The number text to check.
+// * @return nil if text is a JSON number, otherwise an error naming the malformed part.
+// */
+func ValidateNumber(text string) error {
+	index := 0
+	if strings.HasPrefix(text, "-") {
+		index++
+	}
+	end, err := scanDecimal(text, index, &ParseOptions{})
+	if err != nil {
+		return err
+	}
+	if end < len(text) {
+		return fmt.Errorf("unexpected %q after number", text[end])
+	}
+	return nil
+}
+
+// /**
+// * @brief Reports whether the run of number-like characters starting at index is longer than limit.
+// *
+// * @details At most limit+1 bytes are looked at.
+// */
+func numberRunExceeds(jsonStr string, index, limit int) bool {
+	end := index
+	for end < len(jsonStr) && end-index <= limit && (strings.IndexByte("0123456789.eE+-", jsonStr[end]) >= 0 || isIdentStart(jsonStr[end])) {
+		end++
+	}
+	return end-index > limit
+}
+
+// /**
+// * @brief Returns the run of number-like characters starting at index, for quoting in error messages.
+// */
+func numberText(jsonStr string, index int) string {
+	end := index
+	for end < len(jsonStr) && (strings.IndexByte("0123456789.eE+-", jsonStr[end]) >= 0 || isIdentStart(jsonStr[end])) {
+		end++
+	}
+	return jsonStr[index:end]
+}
+
+// / TokenStream manages the sequence of tokens.
+// / Comment tokens are skipped; when comments is non-nil they are recorded against the JSON Pointer
+// / of the value they document.
+type TokenStream struct {
+	tokens []Token
+	index  int
+	opts   *ParseOptions ///< limits and extensions applied while parsing
+
+	src      string              ///< source text, used to tell trailing comments from leading ones
+	comments map[string][]string ///< captured comments by JSON Pointer, or nil when not capturing
+	spans    map[string]Span     ///< source span of every value by JSON Pointer, or nil when not recording
+	path     []string            ///< pointer tokens of the value being parsed
+	depth    int                 ///< number of objects and arrays open around the value being parsed
+	pending  []string            ///< comments waiting for the next value
+	last     string              ///< pointer of the value most recently started or finished
+	prev     Token               ///< last non-comment token returned by Next
+
+	duplicates []string    ///< pointers of repeated keys, collected when opts.DisallowDuplicateKeys is set
+	sizes      map[int]int ///< member counts of large objects by the token index of their '{'
+
+	source *Tokenizer ///< when set, tokens are pulled from it one at a time instead of held up front
+	err    error      ///< first error from source; the stream reports TokenEOF from then on
+}
+
+// /**
+// * @brief Creates a TokenStream over tokens from Tokenize, for consumers that walk the tokens themselves.
+// *
+// * @details Comment tokens are skipped by Next and Peek, and reading past the end keeps returning
+// * TokenEOF, so a consumer needs no bounds checks of its own.
+// *
+// * @param tokens The tokens to read.
+// * @return The new TokenStream, positioned at the first token.
+// */
+func NewTokenStream(tokens []Token) *TokenStream {
+	return &TokenStream{tokens: tokens, opts: &ParseOptions{}}
+}
+
+// /**
+// * @brief Pulls the next token from the source Tokenizer once the buffered ones are used up.
+// *
+// * @details Only the token being looked at is kept, so memory stays flat however long the input is.
+// * The source's io.EOF becomes a TokenEOF; any other error is kept in err and also ends the stream.
+// */
+func (ts *TokenStream) fill() {
+	if ts.source == nil || ts.index < len(ts.tokens) {
+		return
+	}
+	token, err := ts.source.Next()
+	if err != nil {
+		if err != io.EOF && ts.err == nil {
+			ts.err = err
+		}
+		token = Token{Type: TokenEOF, Offset: ts.source.offset, End: ts.source.offset, Line: ts.source.line, Column: ts.source.col}
+	}
+	ts.tokens = append(ts.tokens[:0], token)
+	ts.index = 0
+}
+
+// /**
+// * @brief Skips comment tokens at the current position, recording them when capturing.
+// *
+// * @details A comment on the same line as the preceding token (other than a ':') trails that token and
+// * belongs to the value most recently started or finished. Any other comment leads the next value.
+// */
+func (ts *TokenStream) skipComments() {
+	for ts.fill(); ts.index < len(ts.tokens) && ts.tokens[ts.index].Type == TokenComment; ts.fill() {
+		token := ts.tokens[ts.index]
+		ts.index++
+		if ts.comments == nil {
+			continue
+		}
+		text, _ := token.Value.(string)
+		/// prev has no End until a non-comment token has been read; comments before it all lead.
+		sameLine := ts.prev.End > 0 && ts.prev.Type != TokenColon &&
+			!strings.Contains(ts.src[ts.prev.Offset:token.Offset], "\n")
+		if sameLine {
+			ts.comments[ts.last] = append(ts.comments[ts.last], text)
+		} else {
+			ts.pending = append(ts.pending, text)
+		}
+	}
+}
+
+// /**
+// * @brief Returns the JSON Pointer of the value being parsed.
+// */
+func (ts *TokenStream) pointer() string {
+	var sb strings.Builder
+	for _, token := range ts.path {
+		sb.WriteString("/" + escapePointerToken(token))
+	}
+	return sb.String()
+}
+
+// /**
+// * @brief Marks the start or end of the value at the current path.
+// *
+// * @details Comments waiting for a value are attached to it. At the end of an object or array these
+// * are the comments after its last member.
+// */
+func (ts *TokenStream) markValue() {
+	if ts.comments == nil {
+		return
+	}
+	ts.last = ts.pointer()
+	if len(ts.pending) > 0 {
+		ts.comments[ts.last] = append(ts.comments[ts.last], ts.pending...)
+		ts.pending = nil
+	}
+}
+
+// /**
+// * @brief Advances to the next token in the stream.
+// *
+// * @details Returns the next token or a TokenEOF if the end of the token list is reached.
+// *
+// * @return The next Token.
+// */
+func (ts *TokenStream) Next() Token {
+	ts.skipComments()
+	if ts.index < len(ts.tokens) {
+		token := ts.tokens[ts.index]
+		ts.index++
+		ts.prev = token
+		return token
+	}
+	return Token{Type: TokenEOF}
+}
+
+// /**
+// * @brief Peeks at the next token without advancing the stream.
+// *
+// * @details Returns the next token or a TokenEOF if the end of the token list is reached.
+// *
+// * @return The next Token without consuming it.
+// */
+func (ts *TokenStream) Peek() Token {
+	ts.skipComments()
+	if ts.index < len(ts.tokens) {
+		return ts.tokens[ts.index]
+	}
+	return Token{Type: TokenEOF}
+}
+
+// /**
+// * @brief Rewinds the stream to its first token so the same tokens can be parsed again.
+// *
+// * @details Path tracking is reset too, and comments captured so far are discarded so a second pass
+// * doesn't record them twice.
+// */
+func (ts *TokenStream) Reset() {
+	ts.index = 0
+	ts.path, ts.pending, ts.last, ts.prev = nil, nil, "", Token{}
+	ts.depth = 0
+	ts.duplicates = nil
+	if ts.comments != nil {
+		ts.comments = make(map[string][]string)
+	}
+	if ts.spans != nil {
+		ts.spans = make(map[string]Span)
+	}
+}
+
+// /**
+// * @brief Reports how many tokens have not been consumed yet.
+// *
+// * @details Comment tokens and the final TokenEOF are included in the count.
+// *
+// * @return The number of tokens left in the stream.
+// */
+func (ts *TokenStream) Remaining() int {
+	return len(ts.tokens) - ts.index
+}
+
+// /**
+// * @brief Parses a slice of tokens into a Go data structure.
+// *
+// * @details Initializes a TokenStream and parses the JSON value. Tokens left after the value are an error
+// * unless opts.OnTrailingData says to ignore them or collect them as further documents.
+// *
+// * @param jsonStr The source text the tokens were scanned from, quoted in error messages.
+// * @param tokens The slice of tokens to parse.
+// * @param opts The parse options whose limits are enforced.
+// * @return The parsed JSON value or an error.
+// */
+func parse(jsonStr string, tokens []Token, opts *ParseOptions) (interface{}, error) {
+	ts := &TokenStream{tokens: tokens, index: 0, opts: opts, src: jsonStr}
+	return parseStream(ts)
+}
+
+// /**
+// * @brief Parses the document held by a prepared TokenStream.
+// *
+// * @param ts The TokenStream to read from.
+// * @return The parsed JSON value or an error.
+// */
+func parseStream(ts *TokenStream) (interface{}, error) {
+	if ts.source == nil {
+		ts.sizes = objectSizes(ts.tokens)
+	}
+	value, err := parseDocuments(ts)
+	if err == nil && len(ts.duplicates) > 0 {
+		return nil, &DuplicateKeyError{Paths: ts.duplicates}
+	}
+	return value, err
+}
+
+// / minSizedObject is the member count from which objectSizes records an object; Go maps this small
+// / need no growing anyway.
+const minSizedObject = 8
+
+// /**
+// * @brief Counts the members of every large object in a token slice, for presizing their maps.
+// *
+// * @details A single linear pass over the tokens with a stack of open containers, counting the colons
+// * directly inside each object. This costs one extra walk over the token slice (the input text is not
+// * rescanned) and a small map entry per large object, and saves the repeated rehashing of wide objects
+// * built from an empty map. Unbalanced brackets are tolerated; parsing reports them.
+// *
+// * @param tokens The tokens to scan.
+// * @return The member counts of objects with at least minSizedObject members, by the index of their '{'.
+// */
+func objectSizes(tokens []Token) map[int]int {
+	type open struct {
+		index, members int
+		object         bool
+	}
+	var sizes map[int]int
+	var stack []open
+	for i, token := range tokens {
+		switch token.Type {
+		case TokenObjectStart, TokenArrayStart:
+			stack = append(stack, open{index: i, object: token.Type == TokenObjectStart})
+		case TokenColon:
+			if len(stack) > 0 {
+				stack[len(stack)-1].members++
+			}
+		case TokenObjectEnd, TokenArrayEnd:
+			if len(stack) == 0 {
+				continue
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if top.object && top.members >= minSizedObject {
+				if sizes == nil {
+					sizes = make(map[int]int)
+				}
+				sizes[top.index] = top.members
+			}
+		}
+	}
+	return sizes
+}
+
+// /**
+// * @brief Parses the values left in the stream as documents of their own.
+// *
+// * @param ts The TokenStream to read from.
+// * @param docs The documents parsed so far, which the rest are appended to.
+// * @return All the documents or an error.
+// */
+func parseRest(ts *TokenStream, docs []interface{}) ([]interface{}, error) {
+	for ts.Peek().Type != TokenEOF {
+		doc, err := parseValue(ts)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// /**
+// * @brief Parses the first document and handles whatever follows it according to opts.OnTrailingData.
+// *
+// * @param ts The TokenStream to read from.
+// * @return The parsed JSON value (or all documents, when collecting) or an error.
+// */
+func parseDocuments(ts *TokenStream) (interface{}, error) {
+	opts := ts.opts
+	value, err := parseValue(ts)
+	if err != nil {
+		return nil, err
+	}
+	if ts.Peek().Type == TokenEOF {
+		return value, nil
+	}
+	switch opts.OnTrailingData {
+	case TrailingDataIgnore:
+		return value, nil
+	case TrailingDataCollect:
+		/// Parse every following value as a document of its own.
+		docs, err := parseRest(ts, []interface{}{value})
+		if err != nil {
+			return nil, err
+		}
+		return docs, nil
+	}
+	return nil, trailingDataError(ts.src, ts.Peek().Offset)
+}
+
+// /**
+// * @brief Checks whether a tokenizer failure is really non-JSON text after a complete value.
+// *
+// * @details Called after tokenizing failed. If the input starts with a whole value and trailing data
+// * isn't allowed, what follows it is reported as trailing data rather than as a bad token.
+// *
+// * @param jsonStr The source text.
+// * @param opts The parse options.
+// * @return The trailing data error, or nil if the failure is inside the first value.
+// */
+func trailingText(jsonStr string, opts *ParseOptions) error {
+	if opts.OnTrailingData != TrailingDataError {
+		return nil
+	}
+	_, end, err := parsePrefix(jsonStr, opts)
+	if err != nil {
+		return nil
+	}
+	for {
+		end = skipWhitespace(jsonStr, end)
+		token, next, err := scanToken(newLineCounter(jsonStr), end, opts)
+		if err != nil || token.Type != TokenComment {
+			break
+		}
+		end = next
+	}
+	return trailingDataError(jsonStr, end)
+}
+
+// /**
+// * @brief Builds the error for data following a complete value.
+// *
+// * @details The error is a *SyntaxError whose Offset is where the trailing data begins, so callers can
+// * split concatenated documents there. The message quotes the start of the trailing data.
+// *
+// * @param jsonStr The source text.
+// * @param offset The offset of the first token after the value.
+// * @return The error.
+// */
+func trailingDataError(jsonStr string, offset int) error {
+	const maxSnippet = 20
+	c := newLineCounter(jsonStr)
+	c.advance(min(offset, len(jsonStr)))
+	snippet := jsonStr[min(offset, len(jsonStr)):]
+	if i := strings.IndexAny(snippet, "\r\n"); i >= 0 {
+		snippet = snippet[:i]
+	}
+	if runes := []rune(snippet); len(runes) > maxSnippet {
+		snippet = string(runes[:maxSnippet]) + "..."
+	}
+	return &SyntaxError{
+		Msg:    fmt.Sprintf("extra tokens after value at line %d, col %d (offset %d): %q", c.line, c.col, offset, snippet),
+		Offset: offset,
+	}
+}
+
+// /**
+// * @brief Parses a single JSON value from the token stream.
+// *
+// * @details Dispatches to specific parsing functions based on the token type:
+// * - TokenObjectStart ('{') -> parseObject
+// * - TokenArrayStart ('[') -> parseArray (a []float64, []int64 or []string when opts.TypedSlices allows)
+// * - TokenString -> returns the string value
+// * - TokenNumber -> returns the float64 value (or int64 under opts.PreserveIntegers)
+// * - TokenTrue ('true') -> returns true
+// * - TokenFalse ('false') -> returns false
+// * - TokenNull ('null') -> returns nil
+// * - TokenUndefined ('undefined', lenient only) -> returns nil
+// *
+// * @param ts The TokenStream to read from.
+// * @return The parsed value or an error.
+// */
+func parseValue(ts *TokenStream) (interface{}, error) {
+	token := ts.Next()
+	ts.markValue()
+	if ts.spans != nil {
+		/// Once the value is parsed, prev is its last token: the scalar itself or the closing bracket.
+		defer func() { ts.spans[ts.pointer()] = Span{StartOffset: token.Offset, EndOffset: ts.prev.End} }()
+	}
+	switch token.Type {
+	case TokenObjectStart, TokenArrayStart:
+		/// Bound the recursion so hostile input like "[[[[..." fails cleanly instead of overflowing the stack.
+		if ts.depth >= ts.opts.maxDepth() {
+			return nil, fmt.Errorf("maximum nesting depth exceeded at line %d, col %d", token.Line, token.Column)
+		}
+		ts.depth++
+		defer func() { ts.depth-- }()
+		if token.Type == TokenObjectStart {
+			return parseObject(ts, token)
+		}
+		arr, err := parseArray(ts, token)
+		if err != nil || !ts.opts.TypedSlices {
+			return arr, err
+		}
+		return typedSlice(arr), nil
+	case TokenString:
+		s, ok := token.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("string token at line %d, col %d has no string value (%T)", token.Line, token.Column, token.Value)
+		}
+		return s, nil
+	case TokenNumber:
+		switch num := token.Value.(type) {
+		case float64, int64:
+			return num, nil
+		}
+		return nil, fmt.Errorf("number token at line %d, col %d has no number value (%T)", token.Line, token.Column, token.Value)
+	case TokenTrue:
+		return true, nil
+	case TokenFalse:
+		return false, nil
+	case TokenNull, TokenUndefined:
+		return nil, nil
+	default:
+		return nil, unexpectedToken("", token)
+	}
+}
+
+// /**
+// * @brief Parses a JSON object from the token stream.
+// *
+// * @details Reads key-value pairs until encountering '}', handling:
+// * - Commas (',') between pairs (except before the first pair).
+// * - Colons (':') between keys and values.
+// * - String keys followed by values of any type.
+// *
+// * Objects with more than opts.MaxObjectKeys members are rejected. Members are counted as they are read,
+// * so repeating a key doesn't get an object past the limit.
+// *
+// * @param ts The TokenStream to read from.
+// * @param open The object's '{' token, whose position is used in error messages.
+// * @return A map representing the object or an error.
+// */
+func parseObject(ts *TokenStream, open Token) (map[string]interface{}, error) {
+	/// The '{' was the token just consumed; objectSizes may know how many members follow it.
+	obj := make(map[string]interface{}, ts.sizes[ts.index-1])
+	first, members := true, 0
+	for {
+		token := ts.Peek()
+		if token.Type == TokenObjectEnd {
+			/// Consume the '}' token and return the object.
+			ts.Next()
+			ts.markValue()
+			return obj, nil
+		}
+		if !first {
+			if token.Type != TokenComma {
+				return nil, unexpectedToken("',' or '}'", token)
+			}
+			/// Consume the comma.
+			ts.Next()
+			token = ts.Peek()
+			if token.Type == TokenObjectEnd {
+				if ts.opts.AllowTrailingCommas {
+					continue
+				}
+				return nil, fmt.Errorf("trailing comma before '}' at line %d, col %d", token.Line, token.Column)
+			}
+		}
+		if token.Type == TokenComma {
+			return nil, fmt.Errorf("missing member before ',' at line %d, col %d", token.Line, token.Column)
+		}
+		if token.Type != TokenString && token.Type != TokenIdentifier {
+			return nil, unexpectedToken("a string key", token)
+		}
+		/// Get the key.
+		token = ts.Next()
+		key, ok := token.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("key token at line %d, col %d has no string value (%T)", token.Line, token.Column, token.Value)
+		}
+		members++
+		if max := ts.opts.MaxObjectKeys; max > 0 && members > max {
+			return nil, fmt.Errorf("object at line %d, col %d has more than %d keys", open.Line, open.Column, max)
+		}
+		if _, seen := obj[key]; seen {
+			if ts.opts.OnDuplicateKey != nil {
+				ts.opts.OnDuplicateKey(key, token.Offset)
+			}
+			if ts.opts.DisallowDuplicateKeys {
+				/// Keep going so every duplicate in the document is reported at once.
+				ts.duplicates = append(ts.duplicates, ts.pointer()+"/"+escapePointerToken(key))
+			}
+		}
+		if colon := ts.Next(); colon.Type != TokenColon {
+			return nil, unexpectedToken("':'", colon)
+		}
+		/// Parse the value.
+		ts.path = append(ts.path, key)
+		value, err := parseValue(ts)
+		ts.path = ts.path[:len(ts.path)-1]
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = value
+		first = false
+	}
+}
+
+// /**
+// * @brief Parses a JSON array from the token stream.
+// *
+// * @details Reads values until encountering ']', handling:
+// * - Commas (',') between values (except before the first value).
+// * - Values of any type (objects, arrays, strings, numbers, true, false, null).
+// *
+// * Arrays with more than opts.MaxArrayLen elements are rejected.
+// *
+// * @param ts The TokenStream to read from.
+// * @param open The array's '[' token, whose position is used in error messages.
+// * @return A slice representing the array or an error.
+// */
+func parseArray(ts *TokenStream, open Token) ([]interface{}, error) {
+	var arr []interface{}
+	first := true
+	for {
+		token := ts.Peek()
+		if token.Type == TokenArrayEnd {
+			/// Consume the ']' token and return the array.
+			ts.Next()
+			ts.markValue()
+			return arr, nil
+		}
+		if !first {
+			if token.Type != TokenComma {
+				return nil, unexpectedToken("',' or ']'", token)
+			}
+			/// Consume the comma.
+			ts.Next()
+			if next := ts.Peek(); next.Type == TokenArrayEnd {
+				if ts.opts.AllowTrailingCommas {
+					continue
+				}
+				return nil, fmt.Errorf("trailing comma before ']' at line %d, col %d", next.Line, next.Column)
+			}
+		}
+		/// Even with AllowTrailingCommas, a comma may only follow a value: [1,,2] is missing one.
+		if next := ts.Peek(); next.Type == TokenComma {
+			return nil, fmt.Errorf("missing value before ',' at line %d, col %d", next.Line, next.Column)
+		}
+		/// Parse the value.
+		ts.path = append(ts.path, strconv.Itoa(len(arr)))
+		value, err := parseValue(ts)
+		ts.path = ts.path[:len(ts.path)-1]
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+		if max := ts.opts.MaxArrayLen; max > 0 && len(arr) > max {
+			return nil, fmt.Errorf("array at line %d, col %d has more than %d elements", open.Line, open.Column, max)
+		}
+		first = false
+	}
+}
+
+// /**
+// * @brief Converts a homogeneous array to a concrete slice type, for ParseOptions.TypedSlices.
+// *
+// * @param arr The parsed array.
+// * @return A []float64 if every element is a float64 number, a []int64 if every element is an integer
+// * kept by ParseOptions.PreserveIntegers, a []string if every element is a string, and arr itself
+// * otherwise, including when it is empty or mixes integers with other numbers.
+// */
+func typedSlice(arr []interface{}) interface{} {
+	if len(arr) == 0 {
+		return arr
+	}
+	switch arr[0].(type) {
+	case float64:
+		nums := make([]float64, len(arr))
+		for i, val := range arr {
+			f, ok := val.(float64)
+			if !ok {
+				return arr
+			}
+			nums[i] = f
+		}
+		return nums
+	case int64:
+		ints := make([]int64, len(arr))
+		for i, val := range arr {
+			n, ok := val.(int64)
+			if !ok {
+				return arr
+			}
+			ints[i] = n
+		}
+		return ints
+	case string:
+		strs := make([]string, len(arr))
+		for i, val := range arr {
+			s, ok := val.(string)
+			if !ok {
+				return arr
+			}
+			strs[i] = s
+		}
+		return strs
+	}
+	return arr
+}
+
+// /**
+// * @brief Turns a []float64, []int64 or []string made by ParseOptions.TypedSlices back into a []interface{}.
+// *
+// * @details Code that walks parsed trees switches on the result, so typed arrays are treated like any other.
+// *
+// * @param value A parsed JSON value.
+// * @return The boxed array, or value unchanged if it is not a typed slice.
+// */
+func untypedSlice(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []float64:
+		arr := make([]interface{}, len(v))
+		for i, f := range v {
+			arr[i] = f
+		}
+		return arr
+	case []int64:
+		arr := make([]interface{}, len(v))
+		for i, n := range v {
+			arr[i] = n
+		}
+		return arr
+	case []string:
+		arr := make([]interface{}, len(v))
+		for i, s := range v {
+			arr[i] = s
+		}
+		return arr
+	}
+	return value
+}
+
+// /**
+// * @brief Main entry point to parse a JSON string into a Go data structure.
+// *
+// * @details Combines tokenization and parsing:
+// * - Calls tokenize to break the JSON string into tokens.
+// * - Calls parse to convert tokens into a Go value.
+// *
+// * @param jsonStr The JSON string to parse.
+// * @return The parsed JSON value or an error.
+// */
+func ParseJSON(jsonStr string) (interface{}, error) {
+	return ParseJSONWithOptions(jsonStr, ParseOptions{})
+}
+
+// /**
+// * @brief Parses a JSON5 document.
+// *
+// * @details Supported JSON5 features: // and /* */ comments, trailing commas in objects and arrays,
+// * single-quoted strings (with \' escapes), unquoted object keys made of ASCII letters, digits, '_' and
+// * '$', hexadecimal integers (0xFF), leading and trailing decimal points (.5, 5.), an explicit '+' sign,
+// * and Infinity, -Infinity and NaN.
+// *
+// * Not supported: the extra JSON5 string escapes (\v, \0, \xHH), line continuations inside strings,
+// * non-ASCII identifier characters in unquoted keys, literal words (true, null, ...) as unquoted keys,
+// * and Unicode whitespace beyond space, tab, CR and LF.
+// *
+// * @param jsonStr The JSON5 string to parse.
+// * @return The parsed value or an error.
+// */
+func ParseJSON5(jsonStr string) (interface{}, error) {
+	return ParseJSONWithOptions(jsonStr, JSON5Options())
+}
+
+// /**
+// * @brief Parses a JSON string, accepting the grammar extensions enabled in opts.
+// *
+// * @param jsonStr The JSON string to parse.
+// * @param opts The parse options, e.g. LenientOptions().
+// * @return The parsed JSON value or an error.
+// */
+func ParseJSONWithOptions(jsonStr string, opts ParseOptions) (interface{}, error) {
+	if opts.OnTrailingData == TrailingDataIgnore {
+		/// Whatever follows the value is never scanned, so it needn't even be valid JSON.
+		value, _, err := parsePrefix(jsonStr, &opts)
+		return value, err
+	}
+	tokens, err := tokenizeWithOptions(jsonStr, &opts)
+	if err != nil {
+		if trailing := trailingText(jsonStr, &opts); trailing != nil {
+			return nil, trailing
+		}
+		return nil, err
+	}
+	return parse(jsonStr, tokens, &opts)
+}
+
+// /**
+// * @brief Parses every JSON value in a string as a separate document.
+// *
+// * @details Suits concatenated and newline-delimited JSON. Unlike OnTrailingData set to
+// * TrailingDataCollect, which returns a lone document as itself, the result is always a slice with one
+// * element per document, so a single array isn't mistaken for several documents. Input holding only
+// * whitespace and comments has no documents. opts.OnTrailingData is ignored.
+// *
+// * @param jsonStr The JSON text.
+// * @param opts The parse options applied to every document.
+// * @return The documents in order, or an error.
+// */
+func ParseAll(jsonStr string, opts ParseOptions) ([]interface{}, error) {
+	tokens, err := tokenizeWithOptions(jsonStr, &opts)
+	if err != nil {
+		return nil, err
+	}
+	ts := &TokenStream{tokens: tokens, opts: &opts, src: jsonStr, sizes: objectSizes(tokens)}
+	docs, err := parseRest(ts, []interface{}{})
+	if err == nil && len(ts.duplicates) > 0 {
+		return nil, &DuplicateKeyError{Paths: ts.duplicates}
+	}
+	return docs, err
+}
+
+// /**
+// * @brief Parses the first JSON value in a string and reports how many bytes it used.
+// *
+// * @details Anything after the value is left alone, which suits custom framing where JSON is
+// * followed by other data. Leading whitespace is skipped and counted; trailing whitespace is not.
+// *
+// * @param jsonStr The input, starting with a JSON value.
+// * @return The parsed value, the offset just past it, and any error.
+// */
+func ParsePrefix(jsonStr string) (interface{}, int, error) {
+	return parsePrefix(jsonStr, &ParseOptions{})
+}
+
+// /**
+// * @brief Parses the first JSON value in a string under the given options.
+// *
+// * @param jsonStr The input, starting with a JSON value.
+// * @param opts The parse options.
+// * @return The parsed value, the offset just past it, and any error.
+// */
+func parsePrefix(jsonStr string, opts *ParseOptions) (interface{}, int, error) {
+	start := skipWhitespace(jsonStr, 0)
+	if start >= len(jsonStr) {
+		return nil, 0, fmt.Errorf("unexpected end of input")
+	}
+	end, err := skipValue(jsonStr, start, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	tokens, err := tokenizeWithOptions(jsonStr[start:end], opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	value, err := parse(jsonStr[start:end], tokens, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, end, nil
+}
+
+// /**
+// * @brief Escapes special characters in a string for JSON output.
+// *
+// * @details Quotes, backslashes and the control characters with short forms (\b \f \n \r \t) use
+// * those. Everything else outside printable ASCII (0x20 ' ' through 0x7E '~') is written as \uXXXX:
+// * the remaining C0 controls (below 0x20), DEL (0x7F), the C1 controls (0x80-0x9F) and all non-ASCII
+// * text, so the output is pure ASCII. Runes beyond the Basic Multilingual Plane are written as a UTF-16
+// * surrogate pair (e.g. U+1F600 as \ud83d\ude00), which parseString combines back into one rune.
+// *
+// * @param s The string to escape.
+// * @return The escaped string enclosed in quotes.
+// */
+func escapeString(s string) string {
+	return `"` + escapeStringBody(s) + `"`
+}
+
+// /**
+// * @brief Escapes a string for JSON output without adding the enclosing quotes.
+// *
+// * @details Uses the same escapes as escapeString, for splicing string content into larger output.
+// *
+// * @param s The string to escape.
+// * @return The escaped content, ready to be placed between double quotes.
+// */
+func EscapeStringContent(s string) string {
+	return escapeStringBody(s)
+}
+
+// /**
+// * @brief Writes the escaped form of every character of s, as described for escapeString.
+// *
+// * @param s The string to escape.
+// * @return The escaped content without quotes.
+// */
+func escapeStringBody(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"':
+			/// Escape double quotes.
+			sb.WriteString("\\\"")
+		case '\\':
+			/// Escape backslashes.
+			sb.WriteString("\\\\")
+		case '\b':
+			/// Escape backspace.
+			sb.WriteString("\\b")
+		case '\f':
+			/// Escape formfeed.
+			sb.WriteString("\\f")
+		case '\n':
+			/// Escape newline.
+			sb.WriteString("\\n")
+		case '\r':
+			/// Escape carriage return.
+			sb.WriteString("\\r")
+		case '\t':
+			/// Escape tab.
+			sb.WriteString("\\t")
+		default:
+			if r > 0xFFFF {
+				/// \u takes exactly four hex digits, so astral characters need a surrogate pair.
+				hi, lo := utf16.EncodeRune(r)
+				sb.WriteString(fmt.Sprintf("\\u%04x\\u%04x", hi, lo))
+			} else if r < 32 || r > 126 {
+				/// Escape non-printable characters.
+				sb.WriteString(fmt.Sprintf("\\u%04x", r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// / prettyPrinter writes indented JSON to a writer, remembering the first write error.
+type prettyPrinter struct {
+	w             io.Writer
+	indent        string              ///< indentation unit repeated once per nesting level
+	inlineScalars bool                ///< keep arrays of scalars on one line
+	inlineWidth   int                 ///< when non-zero, inline a scalar array only if its line fits in this many bytes
+	sortKeys      bool                ///< write object members in sorted key order
+	col           int                 ///< bytes written since the last newline
+	suffix        int                 ///< bytes the caller writes right after the current value on its line, i.e. 1 for a ','
+	mark          [2]string           ///< text written before and after the value at markPointer, when set
+	markPointer   string              ///< JSON Pointer of the value to mark
+	pointer       string              ///< JSON Pointer of the value being written, tracked only when marking or commenting
+	comments      map[string][]string ///< comments written above the value with the same JSON Pointer, when set
+	strict        bool                ///< fail on values with no JSON form instead of writing a stand-in
+	invalid       error               ///< first json.RawMessage written as a string stand-in because it isn't valid JSON
+	err           error
+}
+
+// /**
+// * @brief Records an error for a value that can't be written as JSON, unless an earlier error is kept.
+// *
+// * @param format The fmt format of the message.
+// * @param args The format arguments.
+// */
+func (p *prettyPrinter) fail(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+}
+
+// /**
+// * @brief Writes a string unless an earlier write already failed.
+// *
+// * @param s The text to write.
+// */
+func (p *prettyPrinter) write(s string) {
+	if p.err == nil {
+		_, p.err = io.WriteString(p.w, s)
+	}
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.col = len(s) - i - 1
+	} else {
+		p.col += len(s)
+	}
+}
+
+// /**
+// * @brief Recursively formats the JSON value with indentation.
+// *
+// * @param value The JSON value to format.
+// * @param indentLevel The current level of indentation.
+// */
+func (p *prettyPrinter) prettyPrint(value interface{}, indentLevel int) {
+	indent := strings.Repeat(p.indent, indentLevel)
+	marking := p.mark != [2]string{}
+	if marking && p.pointer == p.markPointer {
+		p.write(p.mark[0])
+		defer p.write(p.mark[1])
+	}
+	tracking := marking || p.comments != nil
+	parent := p.pointer
+	defer func() { p.pointer = parent }()
+	switch v := untypedSlice(value).(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		if p.sortKeys {
+			sort.Strings(keys)
+		}
+		if len(keys) == 0 {
+			p.write("{}")
+			return
+		}
+		p.write("{\n")
+		for i, key := range keys {
+			if i > 0 {
+				p.write(",\n")
+			}
+			if tracking {
+				p.pointer = parent + "/" + escapePointerToken(key)
+			}
+			p.writeComments(p.pointer, indent+p.indent)
+			p.write(indent + p.indent + escapeString(key) + ": ")
+			p.suffix = 0
+			if i < len(keys)-1 {
+				p.suffix = len(",")
+			}
+			p.prettyPrint(v[key], indentLevel+1)
+		}
+		p.write("\n" + indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			p.write("[]")
+			return
+		}
+		if p.inlineScalars && allScalars(v) && !p.hasElementComments(parent, len(v)) {
+			/// Short lists of scalars read better on one line, e.g. [1, 2, 3].
+			var sb strings.Builder
+			inline := &prettyPrinter{w: &sb, strict: p.strict, mark: p.mark, markPointer: p.markPointer}
+			inline.write("[")
+			for i, val := range v {
+				if i > 0 {
+					inline.write(", ")
+				}
+				if marking {
+					inline.pointer = parent + "/" + strconv.Itoa(i)
+				}
+				inline.prettyPrint(val, 0)
+			}
+			inline.write("]")
+			/// The ',' after a member that isn't the last also has to fit.
+			if p.inlineWidth == 0 || p.col+sb.Len()+p.suffix <= p.inlineWidth {
+				if inline.err != nil {
+					p.fail("%v", inline.err)
+				}
+				p.write(sb.String())
+				return
+			}
+		}
+		p.write("[\n")
+		first := true
+		for i, val := range v {
+			if !first {
+				p.write(",\n")
+			}
+			if tracking {
+				p.pointer = parent + "/" + strconv.Itoa(i)
+			}
+			p.writeComments(p.pointer, indent+p.indent)
+			p.write(indent + p.indent)
+			p.suffix = 0
+			if i < len(v)-1 {
+				p.suffix = len(",")
+			}
+			p.prettyPrint(val, indentLevel+1)
+			first = false
+		}
+		p.write("\n" + indent + "]")
+	case string:
+		p.write(escapeString(v))
+	case float64:
+		switch {
+		case p.strict && (math.IsInf(v, 0) || math.IsNaN(v)):
+			p.fail("unsupported number %v: JSON has no Infinity or NaN", v)
+		case math.IsInf(v, 1):
+			/// Not valid JSON, but readable by JSON5 (and by this parser with AllowInfinityNaN).
+			p.write("Infinity")
+		case math.IsInf(v, -1):
+			p.write("-Infinity")
+		case math.IsNaN(v):
+			p.write("NaN")
+		default:
+			p.write(FormatNumber(v))
+		}
+	case int64:
+		p.write(strconv.FormatInt(v, 10))
+	case json.Number:
+		/// Numbers decoded by encoding/json with UseNumber keep their original text.
+		p.write(v.String())
+	case json.RawMessage:
+		/// Raw JSON from encoding/json is parsed so it is indented like the rest of the tree.
+		if raw, ok := p.parseRaw(v); ok {
+			p.prettyPrint(raw, indentLevel)
+		}
+	case bool:
+		if v {
+			p.write("true")
+		} else {
+			p.write("false")
+		}
+	case nil:
+		p.write("null")
+	default:
+		if p.strict {
+			p.fail("unsupported type %T", v)
+			return
+		}
+		p.write("unknown type")
+	}
+}
+
+// /**
+// * @brief Parses a json.RawMessage so it can be written like the rest of the tree.
+// *
+// * @details Text that isn't valid JSON fails a strict printer. Any other printer writes it as a JSON
+// * string instead, so the output stays valid, and remembers the error for StreamPretty to return.
+// *
+// * @param v The raw message.
+// * @return The parsed value, and false if the message was handled here because it isn't valid JSON.
+// */
+func (p *prettyPrinter) parseRaw(v json.RawMessage) (interface{}, bool) {
+	raw, err := ParseJSON(string(v))
+	if err == nil {
+		return raw, true
+	}
+	err = fmt.Errorf("invalid json.RawMessage: %v", err)
+	if p.strict {
+		p.fail("%v", err)
+		return nil, false
+	}
+	if p.invalid == nil {
+		p.invalid = err
+	}
+	p.write(escapeString(string(v)))
+	return nil, false
+}
+
+// /**
+// * @brief Writes the comments recorded for a value, one per line, above it.
+// *
+// * @details Single-line comments are written as // comments and multi-line ones as /* */ blocks.
+// *
+// * @param pointer The JSON Pointer of the value about to be written.
+// * @param indent The indentation of the value's line.
+// */
+func (p *prettyPrinter) writeComments(pointer, indent string) {
+	for _, text := range p.comments[pointer] {
+		if strings.Contains(text, "\n") {
+			p.write(indent + "/* " + text + " */\n")
+		} else {
+			p.write(indent + "// " + text + "\n")
+		}
+	}
+}
+
+// /**
+// * @brief Reports whether any element of an array has comments, which keeps the array off one line.
+// *
+// * @param pointer The JSON Pointer of the array.
+// * @param n The number of elements.
+// * @return True if some element has comments to write.
+// */
+func (p *prettyPrinter) hasElementComments(pointer string, n int) bool {
+	if p.comments == nil {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if len(p.comments[pointer+"/"+strconv.Itoa(i)]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// /**
+// * @brief Reports whether no element of the array is an object or array.
+// *
+// * @param arr The array to check.
+// * @return True if every element is a scalar.
+// */
+func allScalars(arr []interface{}) bool {
+	for _, val := range arr {
+		switch val.(type) {
+		case map[string]interface{}, []interface{}, []float64, []int64, []string, json.RawMessage:
+			return false
+		}
+	}
+	return true
+}
+
+// /**
+// * @brief Writes the JSON value on a single line, without any insignificant whitespace.
+// *
+// * @param value The JSON value to format.
+// * @param sortKeys Whether object members are written in sorted key order.
+// */
+func (p *prettyPrinter) writeCompact(value interface{}, sortKeys bool) {
+	switch v := untypedSlice(value).(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		if sortKeys {
+			sort.Strings(keys)
+		}
+		p.write("{")
+		for i, key := range keys {
+			if i > 0 {
+				p.write(",")
+			}
+			p.write(escapeString(key) + ":")
+			p.writeCompact(v[key], sortKeys)
+		}
+		p.write("}")
+	case []interface{}:
+		p.write("[")
+		for i, val := range v {
+			if i > 0 {
+				p.write(",")
+			}
+			p.writeCompact(val, sortKeys)
+		}
+		p.write("]")
+	case json.RawMessage:
+		if raw, ok := p.parseRaw(v); ok {
+			p.writeCompact(raw, sortKeys)
+		}
+	default:
+		/// Scalars look the same either way.
+		p.prettyPrint(v, 0)
+	}
+}
+
+// /**
+// * @brief Formats the JSON value on a single line with sorted keys.
+// *
+// * @param jsonValue The JSON value to format.
+// * @return The compact JSON text.
+// */
+func compactString(jsonValue interface{}) string {
+	var sb strings.Builder
+	p := &prettyPrinter{w: &sb}
+	p.writeCompact(jsonValue, true)
+	return sb.String()
+}
+
+// /**
+// * @brief Formats a number the same way everywhere: in PrettyPrint, Marshal, tables and the viewer.
+// *
+// * @details Integral values within ±2^53 are written as plain integers (1000000, not 1e+06); anything
+// * else gets the shortest text that parses back to the same float64, as strconv.FormatFloat(v, 'g',
+// * -1, 64) writes it. Infinity and NaN have no JSON form; callers that may meet them check first.
+// *
+// * @param v The number.
+// * @return The number's text.
+// */
+func FormatNumber(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) <= maxSafeInteger {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// /**
+// * @brief Formats the JSON value into a pretty-printed string, indented two spaces per level.
+// *
+// * @details A json.RawMessage that isn't valid JSON is written as a string holding its text, so the
+// * output is always valid JSON; use StreamPretty or Marshal to have it reported as an error.
+// *
+// * @param jsonValue The JSON value to format.
+// * @return A pretty-printed JSON string.
+// */
+func PrettyPrint(jsonValue interface{}) string {
+	return PrettyPrintIndent(jsonValue, "  ")
+}
+
+// /**
+// * @brief Formats the JSON value into a pretty-printed string with the given indentation unit.
+// *
+// * @param jsonValue The JSON value to format.
+// * @param indent The text written once per nesting level, e.g. "\t" or four spaces.
+// * @return A pretty-printed JSON string.
+// */
+func PrettyPrintIndent(jsonValue interface{}, indent string) string {
+	var sb strings.Builder
+	StreamPretty(&sb, jsonValue, indent)
+	return sb.String()
+}
+
+// /**
+// * @brief Formats the JSON value into a pretty-printed string with the given layout options.
+// *
+// * @param jsonValue The JSON value to format.
+// * @param opts The layout options.
+// * @return A pretty-printed JSON string.
+// */
+func PrettyPrintWithOptions(jsonValue interface{}, opts PrettyOptions) string {
+	indent := opts.Indent
+	if indent == "" {
+		indent = "  "
+	}
+	var sb strings.Builder
+	p := &prettyPrinter{w: &sb, indent: indent, inlineScalars: opts.InlineScalarArrays, sortKeys: opts.SortKeys}
+	p.prettyPrint(jsonValue, 0)
+	return sb.String()
+}
+
+// /**
+// * @brief Formats the JSON value in the one fixed layout meant for checked-in JSON files.
+// *
+// * @details The output is stable for equal values: object members are sorted by key, indentation is two
+// * spaces, arrays of scalars stay on one line when that line fits in canonicalWidth columns, and the text
+// * ends in a newline. This is for readable, diff-friendly fixtures, not the byte-exact canonical form
+// * used for hashing (RFC 8785).
+// *
+// * @param jsonValue The JSON value to format.
+// * @return The formatted JSON text ending in '\n'.
+// */
+func PrettyPrintCanonicalDisplay(jsonValue interface{}) string {
+	var sb strings.Builder
+	p := &prettyPrinter{w: &sb, indent: "  ", inlineScalars: true, inlineWidth: canonicalWidth, sortKeys: true}
+	p.prettyPrint(jsonValue, 0)
+	return sb.String() + "\n"
+}
+
+// /**
+// * @brief Formats the JSON value like PrettyPrint with the value at pointer shown in reverse video.
+// *
+// * @details Meant for terminals; use PrettyPrintMarked to point at a value in plain text. If the pointer
+// * doesn't resolve, nothing is highlighted.
+// *
+// * @param jsonValue The JSON value to format.
+// * @param pointer The RFC 6901 JSON Pointer of the value to highlight ("" for the root).
+// * @return The pretty-printed JSON string with ANSI escape codes around the highlighted value.
+// */
+func PrettyPrintHighlight(jsonValue interface{}, pointer string) string {
+	return PrettyPrintMarked(jsonValue, pointer, "\x1b[7m", "\x1b[0m")
+}
+
+// /**
+// * @brief Formats the JSON value like PrettyPrint with markers around the value at pointer.
+// *
+// * @details For example, open ">>> " and close " <<<" point readers at one field of an error report.
+// * If the pointer doesn't resolve, no markers are written.
+// *
+// * @param jsonValue The JSON value to format.
+// * @param pointer The RFC 6901 JSON Pointer of the value to mark ("" for the root).
+// * @param open The text written before the value.
+// * @param close The text written after the value.
+// * @return The pretty-printed JSON string.
+// */
+func PrettyPrintMarked(jsonValue interface{}, pointer, open, close string) string {
+	var sb strings.Builder
+	p := &prettyPrinter{w: &sb, indent: "  ", mark: [2]string{open, close}, markPointer: pointer}
+	p.prettyPrint(jsonValue, 0)
+	return sb.String()
+}
+
+// /**
+// * @brief Formats the JSON value like PrettyPrint, followed by a trailing newline.
+// *
+// * @details Matches what tools such as jq emit, so files written with it don't produce a
+// * "no newline at end of file" diff.
+// *
+// * @param jsonValue The JSON value to format.
+// * @return A pretty-printed JSON string ending in '\n'.
+// */
+func PrettyPrintln(jsonValue interface{}) string {
+	return PrettyPrint(jsonValue) + "\n"
+}
+
+// /**
+// * @brief Pretty-prints the JSON value incrementally to a writer.
+// *
+// * @details Unlike PrettyPrint, the formatted document is never held in memory as a whole, so huge
+// * trees can be written straight to a file or stdout. Output is buffered and flushed before returning.
+// * A json.RawMessage that isn't valid JSON is written as a string holding its text, and reported as the
+// * error once the rest of the value has been written.
+// *
+// * @param w The writer receiving the formatted JSON.
+// * @param v The JSON value to format.
+// * @param indent The indentation unit written once per nesting level (e.g. "  " or "\t").
+// * @return The first write error, else the first invalid json.RawMessage, or nil.
+// */
+func StreamPretty(w io.Writer, v interface{}, indent string) error {
+	bw := bufio.NewWriter(w)
+	p := &prettyPrinter{w: bw, indent: indent}
+	p.prettyPrint(v, 0)
+	if p.err != nil {
+		return p.err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return p.invalid
+}
@@ -0,0 +1,1430 @@
+package jsonparser
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestMaxNumberLen(t *testing.T) {
+	long := "0." + strings.Repeat("1", 1500)
+	tests := []struct {
+		name    string
+		input   string
+		limit   int
+		wantErr string
+	}{
+		{"unlimited by default", long, 0, ""},
+		{"within the limit", "12345", 5, ""},
+		{"over the limit", "123456", 5, "longer than 5 bytes"},
+		{"long fraction over the limit", "0." + strings.Repeat("1", 20), 10, "longer than 10 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONWithOptions(tt.input, ParseOptions{MaxNumberLen: tt.limit})
+			checkErr(t, err, tt.wantErr)
+			_, err = NewDecoderWithOptions(strings.NewReader(tt.input), ParseOptions{MaxNumberLen: tt.limit}).Decode()
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMaxNumberLenRejectsHugeRunPromptly(t *testing.T) {
+	huge := strings.Repeat("9", 1<<20)
+	start := time.Now()
+	_, err := ParseJSONWithOptions("["+huge+"]", ParseOptions{MaxNumberLen: 100})
+	checkErr(t, err, "longer than 100 bytes")
+	_, err = NewDecoderWithOptions(strings.NewReader(huge), ParseOptions{MaxNumberLen: 100}).Decode()
+	checkErr(t, err, "longer than 100 bytes")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("rejecting a 1MB digit run took %v", elapsed)
+	}
+}
+
+// checkErr fails the test unless err contains want, or is nil when want is empty.
+func checkErr(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case want != "" && err == nil:
+		t.Errorf("expected an error containing %q", want)
+	case want != "" && !strings.Contains(err.Error(), want):
+		t.Errorf("error %q does not contain %q", err, want)
+	}
+}
+
+func TestContainerLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    ParseOptions
+		wantErr string
+	}{
+		{"object within limit", `{"a":1,"b":2}`, ParseOptions{MaxObjectKeys: 2}, ""},
+		{"object too many keys", `{"a":1,"b":2,"c":3}`, ParseOptions{MaxObjectKeys: 2}, "object at line 1, col 1 has more than 2 keys"},
+		{"nested object too many keys", `{"x": {"a":1,"b":2,"c":3}}`, ParseOptions{MaxObjectKeys: 2}, "object at line 1, col 7 has more than 2 keys"},
+		{"repeated key counts every member", `{"a":1,"a":2,"a":3}`, ParseOptions{MaxObjectKeys: 2}, "object at line 1, col 1 has more than 2 keys"},
+		{"array within limit", `[1,2,3]`, ParseOptions{MaxArrayLen: 3}, ""},
+		{"array too long", `[1,2,3,4]`, ParseOptions{MaxArrayLen: 3}, "array at line 1, col 1 has more than 3 elements"},
+		{"nested array too long", `{"list": [1,2,3,4]}`, ParseOptions{MaxArrayLen: 3}, "array at line 1, col 10 has more than 3 elements"},
+		{"object on a later line", "[\n  1,\n  {\"a\":1,\"b\":2,\"c\":3}\n]", ParseOptions{MaxObjectKeys: 2}, "object at line 3, col 3 has more than 2 keys"},
+		{"array on a later line", "{\n  \"list\": [1,2,3,4]\n}", ParseOptions{MaxArrayLen: 3}, "array at line 2, col 11 has more than 3 elements"},
+		{"unlimited by default", `{"a":[1,2,3,4,5],"b":1,"c":2}`, ParseOptions{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONWithOptions(tt.input, tt.opts)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTokenizeRecoverPositions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"one error", `[tru]`, []string{"line 1, col 2"}},
+		{"errors on later lines", "[\n  1,\n  @,\n  \"é\", nope\n]", []string{"line 3, col 3", "line 4, col 8"}},
+		{"escape inside a string", "{\n\"a\\q\": 1}", []string{"line 2, col 4"}},
+		{"unterminated string", "[1,\n \"abc", []string{"line 2, col 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := TokenizeRecover(tt.input)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got %d errors %v, want %d", len(errs), errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(errs[i].Msg, want) {
+					t.Errorf("error %d %q does not mention %q", i, errs[i].Msg, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenizeRecover(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     ParseOptions
+		want     []TokenType
+		wantErrs int
+	}{
+		{"valid input", `{"a": [1, true]}`, ParseOptions{},
+			[]TokenType{TokenObjectStart, TokenString, TokenColon, TokenArrayStart, TokenNumber, TokenComma, TokenTrue, TokenArrayEnd, TokenObjectEnd, TokenEOF}, 0},
+		{"two separate errors", `{"a": tru, "b": @}`, ParseOptions{},
+			[]TokenType{TokenObjectStart, TokenString, TokenColon, TokenError, TokenComma, TokenString, TokenColon, TokenError, TokenObjectEnd, TokenEOF}, 2},
+		{"broken string resumes on the next line", "[\"abc\n, 1]", ParseOptions{},
+			[]TokenType{TokenArrayStart, TokenError, TokenComma, TokenNumber, TokenArrayEnd, TokenEOF}, 1},
+		{"comment is an error in strict mode", "[1 // note\n]", ParseOptions{},
+			[]TokenType{TokenArrayStart, TokenNumber, TokenError, TokenError, TokenArrayEnd, TokenEOF}, 2},
+		{"comment is a token with AllowComments", "[1 // note\n]", ParseOptions{AllowComments: true},
+			[]TokenType{TokenArrayStart, TokenNumber, TokenComment, TokenArrayEnd, TokenEOF}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, errs := TokenizeRecoverWithOptions(tt.input, tt.opts)
+			got := make([]TokenType, len(tokens))
+			for i, token := range tokens {
+				got[i] = token.Type
+				if token.Type == TokenError {
+					if _, ok := token.Value.(*SyntaxError); !ok {
+						t.Errorf("error token %d holds %T, want *SyntaxError", i, token.Value)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("token types %v, want %v", got, tt.want)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("got %d errors %v, want %d", len(errs), errs, tt.wantErrs)
+			}
+			if tt.wantErrs == 0 {
+				strict, err := TokenizeWithOptions(tt.input, tt.opts)
+				if err != nil || !reflect.DeepEqual(tokens, strict) {
+					t.Errorf("recovering tokenizer disagrees with TokenizeWithOptions on valid input (err %v)", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSyntaxErrorRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"middle of a document", "{\n  \"a\": 1,\n  \"b\": tru,\n  \"c\": 3\n}",
+			"invalid token at line 3, col 8, expected 'true'\n" +
+				"2 |   \"a\": 1,\n" +
+				"3 |   \"b\": tru,\n" +
+				"  |        ^\n" +
+				"4 |   \"c\": 3\n"},
+		{"first line", "@\n[]", "unexpected character at line 1, col 1: @\n" +
+			"1 | @\n" +
+			"  | ^\n" +
+			"2 | []\n"},
+		{"tab indented", "{\n\t\"a\": x\n}", "unexpected character at line 2, col 7: x\n" +
+			"1 | {\n" +
+			"2 | \t\"a\": x\n" +
+			"  | \t     ^\n" +
+			"3 | }\n"},
+		{"multi-byte characters count once", `{"é": x}`, "unexpected character at line 1, col 7: x\n" +
+			"1 | {\"é\": x}\n" +
+			"  |       ^\n"},
+		{"CRLF line endings", "[\r\n1,\r\n@\r\n]", "unexpected character at line 3, col 1: @\n" +
+			"2 | 1,\n" +
+			"3 | @\n" +
+			"  | ^\n" +
+			"4 | ]\n"},
+		{"line numbers are aligned", "[\n1,\n2,\n3,\n4,\n5,\n6,\n7,\n8,\n@\n]", "unexpected character at line 10, col 1: @\n" +
+			" 9 | 8,\n" +
+			"10 | @\n" +
+			"   | ^\n" +
+			"11 | ]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := TokenizeRecover(tt.input)
+			if len(errs) == 0 {
+				t.Fatal("no error")
+			}
+			if got := errs[0].Render(tt.input); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxErrorRenderTrailingData(t *testing.T) {
+	const input = "{}\n\n  x"
+	_, err := ParseJSON(input)
+	var se *SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("error %v is not a *SyntaxError", err)
+	}
+	want := "extra tokens after value at line 3, col 3 (offset 6): \"x\"\n" +
+		"2 | \n" +
+		"3 |   x\n" +
+		"  |   ^\n"
+	if got := se.Render(input); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTokenizeRecoverManyErrorsPromptly(t *testing.T) {
+	const lines = 50000
+	input := strings.Repeat("@\n", lines)
+	start := time.Now()
+	_, errs := TokenizeRecover(input)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("reporting %d errors took %v", lines, elapsed)
+	}
+	if len(errs) != lines {
+		t.Fatalf("got %d errors, want %d", len(errs), lines)
+	}
+	if want := "line 50000, col 1"; !strings.Contains(errs[lines-1].Msg, want) {
+		t.Errorf("last error %q does not mention %q", errs[lines-1].Msg, want)
+	}
+}
+
+func TestTokenStreamReset(t *testing.T) {
+	tokens, err := Tokenize(`{"a": [1, true]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		consumed int
+	}{
+		{"nothing consumed", 0},
+		{"one token consumed", 1},
+		{"partly consumed", 4},
+		{"everything consumed", len(tokens)},
+		{"read past the end", len(tokens) + 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewTokenStream(tokens)
+			for i := 0; i < tt.consumed; i++ {
+				ts.Next()
+			}
+			ts.Reset()
+			if got := ts.Remaining(); got != len(tokens) {
+				t.Errorf("Remaining after Reset = %d, want %d", got, len(tokens))
+			}
+			if got := ts.Peek(); got != tokens[0] {
+				t.Errorf("Peek after Reset = %v, want %v", got, tokens[0])
+			}
+			for i, want := range tokens {
+				if got := ts.Next(); got != want {
+					t.Fatalf("token %d after Reset = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenStreamResetReparses(t *testing.T) {
+	const src = "// top\n{\"a\": [1, 2] // a\n}"
+	opts := ParseOptions{AllowComments: true}
+	tokens, err := tokenizeWithOptions(src, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := &TokenStream{tokens: tokens, opts: &opts, src: src, comments: make(map[string][]string)}
+	first, err := parseStream(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.Reset()
+	second, err := parseStream(ts)
+	if err != nil {
+		t.Fatalf("second parse after Reset: %v", err)
+	}
+	if !Equal(first, second) {
+		t.Errorf("second parse = %v, want %v", second, first)
+	}
+	want := map[string][]string{"": {"top"}, "/a": {"a"}}
+	if !reflect.DeepEqual(ts.comments, want) {
+		t.Errorf("comments after reparsing = %q, want %q", ts.comments, want)
+	}
+}
+
+func TestRawMessagePrinting(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantPretty string
+		wantErr    string
+	}{
+		{"valid object", `{"b":1, "a":[true]}`, "{\n  \"x\": {\n    \"a\": [\n      true\n    ],\n    \"b\": 1\n  }\n}", ""},
+		{"valid scalar", ` 12 `, "{\n  \"x\": 12\n}", ""},
+		{"truncated", `{"a":`, "{\n  \"x\": \"{\\\"a\\\":\"\n}", "invalid json.RawMessage"},
+		{"not json", `nope`, "{\n  \"x\": \"nope\"\n}", "invalid json.RawMessage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := map[string]interface{}{"x": json.RawMessage(tt.raw)}
+			pretty := PrettyPrintWithOptions(v, PrettyOptions{Indent: "  ", SortKeys: true})
+			if pretty != tt.wantPretty {
+				t.Errorf("PrettyPrint = %q, want %q", pretty, tt.wantPretty)
+			}
+			if _, err := ParseJSON(pretty); err != nil {
+				t.Errorf("PrettyPrint output is not valid JSON: %v", err)
+			}
+			if _, err := ParseJSON(compactString(v)); err != nil {
+				t.Errorf("compact output %q is not valid JSON: %v", compactString(v), err)
+			}
+			var sb strings.Builder
+			checkErr(t, StreamPretty(&sb, v, "  "), tt.wantErr)
+			if _, err := ParseJSON(sb.String()); err != nil {
+				t.Errorf("StreamPretty output is not valid JSON: %v", err)
+			}
+			_, err := Marshal(v)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestEscapeStringBoundaries(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"last C0 control 0x1F", "\x1f", `"\u001f"`},
+		{"space 0x20", " ", `" "`},
+		{"tilde 0x7E", "~", `"~"`},
+		{"DEL 0x7F", "\x7f", `"\u007f"`},
+		{"last C1 control 0x9F", "\u009f", `"\u009f"`},
+		{"no-break space 0xA0", "\u00a0", `"\u00a0"`},
+		{"NUL", "\x00", `"\u0000"`},
+		{"short forms", "\b\f\n\r\t", `"\b\f\n\r\t"`},
+		{"quote and backslash", `"\`, `"\"\\"`},
+		{"printable ASCII", "a~ z", `"a~ z"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := escapeString(tt.in)
+			if got != tt.want {
+				t.Errorf("escapeString(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+			back, err := ParseJSON(got)
+			if err != nil || back != tt.in {
+				t.Errorf("ParseJSON(%s) = %q, %v; want %q", got, back, err, tt.in)
+			}
+		})
+	}
+}
+
+func TestEscapeDELIsShortest(t *testing.T) {
+	// JSON has no short escape for DEL, so the six-byte \u form is the shortest escape.
+	if got := EscapeStringContent("\x7f"); got != `\u007f` {
+		t.Errorf("EscapeStringContent(DEL) = %s, want \\u007f", got)
+	}
+}
+
+func TestEscapeStringContent(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain text", "hello", "hello"},
+		{"quotes", `say "hi"`, `say \"hi\"`},
+		{"backslash", `C:\dir`, `C:\\dir`},
+		{"short forms", "a\tb\nc", `a\tb\nc`},
+		{"control character", "\x01", `\u0001`},
+		{"non-ASCII", "caf\u00e9", `caf\u00e9`},
+		{"astral rune", "\U0001F600", `\ud83d\ude00`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EscapeStringContent(tt.in)
+			if got != tt.want {
+				t.Errorf("EscapeStringContent(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+			if quoted := `"` + got + `"`; quoted != escapeString(tt.in) {
+				t.Errorf("quoted content %s != escapeString %s", quoted, escapeString(tt.in))
+			}
+			// Spliced into a larger document, the content reads back as the original string.
+			doc, err := ParseJSON(`{"msg": "<` + got + `>"}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg := doc.(map[string]interface{})["msg"]; msg != "<"+tt.in+">" {
+				t.Errorf("spliced value = %q, want %q", msg, "<"+tt.in+">")
+			}
+		})
+	}
+}
+
+func TestSurrogatePairs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"emoji pair", `"\uD83D\uDE00"`, "\U0001F600", ""},
+		{"lowercase hex", `"\ud83d\ude00"`, "\U0001F600", ""},
+		{"pair between text", `"a\uD834\uDD1Eb"`, "a\U0001D11Eb", ""},
+		{"last code point", `"\uDBFF\uDFFF"`, "\U0010FFFF", ""},
+		{"BMP escape", `"\u00e9"`, "\u00e9", ""},
+		{"lone high surrogate", `"\uD83D"`, "", "must be followed by a \\uDC00-\\uDFFF low surrogate"},
+		{"high surrogate then text", `"\uD83Dx"`, "", "must be followed by"},
+		{"high surrogate then BMP escape", `"\uD83D\u0041"`, "", "must be followed by"},
+		{"two high surrogates", `"\uD83D\uD83D"`, "", "must be followed by"},
+		{"lone low surrogate", `"\uDE00"`, "", "unpaired low surrogate \\uDE00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSON(tt.input)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && got != tt.want {
+				t.Errorf("ParseJSON(%s) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmojiRoundTrip(t *testing.T) {
+	doc := map[string]interface{}{"face": "\U0001F600", "mixed": "x\U0001F600y\U0001D11E"}
+	pretty := PrettyPrint(doc)
+	if !strings.Contains(pretty, `\ud83d\ude00`) {
+		t.Errorf("PrettyPrint did not escape the emoji as a surrogate pair: %s", pretty)
+	}
+	back, err := ParseJSON(pretty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(back, doc) {
+		t.Errorf("round trip gave %v, want %v", back, doc)
+	}
+}
+
+// json5Example is the example document from json5.org, without the "lineBreaks" member: line
+// continuations inside strings are one of the JSON5 features ParseJSON5 documents as unsupported.
+const json5Example = `// JSON5 example
+{
+  // comments
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here',
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  trailingComma: 'in objects', andIn: ['arrays',],
+  "backwardsCompatible": "with JSON",
+}`
+
+func TestParseJSON5SpecExample(t *testing.T) {
+	got, err := ParseJSON5(json5Example)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"unquoted":            "and you can quote me on that",
+		"singleQuotes":        `I can use "double quotes" here`,
+		"hexadecimal":         float64(0xdecaf),
+		"leadingDecimalPoint": 0.8675309,
+		"andTrailing":         8675309.0,
+		"positiveSign":        1.0,
+		"trailingComma":       "in objects",
+		"andIn":               []interface{}{"arrays"},
+		"backwardsCompatible": "with JSON",
+	}
+	if !Equal(got, want) {
+		t.Errorf("ParseJSON5 = %v, want %v", got, want)
+	}
+}
+
+func TestParseJSON5(t *testing.T) {
+	inf := math.Inf(1)
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr string
+	}{
+		// Examples from the sections of the JSON5 specification.
+		{"empty object", `{}`, map[string]interface{}{}, ""},
+		{"object with trailing comma", `{ width: 1920, height: 1080, }`, map[string]interface{}{"width": 1920.0, "height": 1080.0}, ""},
+		{"nested object", `{ image: { width: 1920, height: 1080, 'aspect-ratio': '16:9', } }`,
+			map[string]interface{}{"image": map[string]interface{}{"width": 1920.0, "height": 1080.0, "aspect-ratio": "16:9"}}, ""},
+		{"identifier characters", `{ $_key1: 1, _: 2 }`, map[string]interface{}{"$_key1": 1.0, "_": 2.0}, ""},
+		{"array with trailing comma", `[ 1, true, 'three', ]`, []interface{}{1.0, true, "three"}, ""},
+		{"nested arrays", `[ [1, true, 'three',], [4, "five", 0x6], ]`,
+			[]interface{}{[]interface{}{1.0, true, "three"}, []interface{}{4.0, "five", 6.0}}, ""},
+		{"single-quoted string", `'Lorem ipsum dolor sit amet, consectetur adipiscing elit.'`, "Lorem ipsum dolor sit amet, consectetur adipiscing elit.", ""},
+		{"apostrophe in double quotes", `"I can't wait"`, "I can't wait", ""},
+		{"escaped single quote", `'I can\'t wait'`, "I can't wait", ""},
+		{"integer", `123`, 123.0, ""},
+		{"fraction", `123.456`, 123.456, ""},
+		{"leading decimal point", `.456`, 0.456, ""},
+		{"trailing decimal point", `123.`, 123.0, ""},
+		{"plus sign", `+123`, 123.0, ""},
+		{"minus sign", `-123`, -123.0, ""},
+		{"hexadecimal", `0xC8`, 200.0, ""},
+		{"negative hexadecimal", `-0xC8`, -200.0, ""},
+		{"exponent", `{ withExponent: 123e-4 }`, map[string]interface{}{"withExponent": 0.0123}, ""},
+		{"infinity", `Infinity`, inf, ""},
+		{"negative infinity", `-Infinity`, -inf, ""},
+		{"comments", "// This is a single line comment.\n/* This is a multi-\n   line comment. */\n[1]", []interface{}{1.0}, ""},
+
+		// JSON5 features documented as unsupported.
+		{"line continuation", "'Look, Mom! \\\nNo newlines!'", nil, "invalid escape"},
+		{"hex escape", `'\x41'`, nil, "invalid escape"},
+		{"vertical tab escape", `'\v'`, nil, "invalid escape"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSON5(tt.input)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && !Equal(got, tt.want) {
+				t.Errorf("ParseJSON5(%s) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSON5NaN(t *testing.T) {
+	got, err := ParseJSON5(`[NaN, -NaN, +Infinity]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := got.([]interface{})
+	if !math.IsNaN(arr[0].(float64)) || !math.IsNaN(arr[1].(float64)) || !math.IsInf(arr[2].(float64), 1) {
+		t.Errorf("ParseJSON5 = %v, want [NaN NaN +Inf]", arr)
+	}
+}
+
+func TestOnTrailingData(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    TrailingDataMode
+		input   string
+		want    interface{}
+		wantErr string
+	}{
+		{"error", TrailingDataError, `{} {}`, nil, "extra tokens after value at line 1, col 4"},
+		{"ignore", TrailingDataIgnore, `{} {}`, map[string]interface{}{}, ""},
+		{"ignore leaves the rest unscanned", TrailingDataIgnore, `{} @#!`, map[string]interface{}{}, ""},
+		{"collect", TrailingDataCollect, `{} {}`, []interface{}{map[string]interface{}{}, map[string]interface{}{}}, ""},
+		{"collect a lone document", TrailingDataCollect, `{}`, map[string]interface{}{}, ""},
+		{"collect stops at a bad document", TrailingDataCollect, `{} {`, nil, "'end of input' at line 1, col 5"},
+		{"error without trailing data", TrailingDataError, `{}`, map[string]interface{}{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONWithOptions(tt.input, ParseOptions{OnTrailingData: tt.mode})
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && !Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []interface{}
+		wantErr string
+	}{
+		{"two documents", `{} {}`, []interface{}{map[string]interface{}{}, map[string]interface{}{}}, ""},
+		{"one array", `[1, 2]`, []interface{}{[]interface{}{1.0, 2.0}}, ""},
+		{"newline delimited", "{\"a\": 1}\n{\"a\": 2}\n", []interface{}{map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 2.0}}, ""},
+		{"scalars", `1 "two" null`, []interface{}{1.0, "two", nil}, ""},
+		{"empty input", " \n", []interface{}{}, ""},
+		{"bad document", `{} [1,`, nil, "'end of input' at line 1, col 7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAll(tt.input, ParseOptions{})
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && !Equal(got, tt.want) {
+				t.Errorf("ParseAll = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAllDuplicateKeys(t *testing.T) {
+	_, err := ParseAll(`{"a": 1} {"b": 1, "b": 2}`, ParseOptions{DisallowDuplicateKeys: true})
+	checkErr(t, err, "/b")
+}
+
+func TestPreserveIntegers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+		print string
+	}{
+		{"beyond float precision", `9007199254740993`, int64(9007199254740993), "9007199254740993"},
+		{"negative", `-42`, int64(-42), "-42"},
+		{"fraction stays float", `1.5`, 1.5, "1.5"},
+		{"exponent stays float", `1e3`, 1000.0, "1000"},
+		{"beyond int64 stays float", `9223372036854775808`, 9223372036854775808.0, "9.223372036854776e+18"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONWithOptions(tt.input, ParseOptions{PreserveIntegers: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("parsed %#v, want %#v", got, tt.want)
+			}
+			if out := PrettyPrint(got); out != tt.print {
+				t.Errorf("PrettyPrint = %s, want %s", out, tt.print)
+			}
+		})
+	}
+}
+
+func TestTypedSlices(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+		want  interface{}
+	}{
+		{"numbers", `[1, 2.5]`, ParseOptions{TypedSlices: true}, []float64{1, 2.5}},
+		{"strings", `["a", "b"]`, ParseOptions{TypedSlices: true}, []string{"a", "b"}},
+		{"mixed", `[1, "b"]`, ParseOptions{TypedSlices: true}, []interface{}{1.0, "b"}},
+		{"empty", `[]`, ParseOptions{TypedSlices: true}, []interface{}(nil)},
+		{"preserved integers", `[1, 9007199254740993]`, ParseOptions{TypedSlices: true, PreserveIntegers: true}, []int64{1, 9007199254740993}},
+		{"integers mixed with floats", `[1, 2.5]`, ParseOptions{TypedSlices: true, PreserveIntegers: true}, []interface{}{int64(1), 2.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+			untyped := tt.opts
+			untyped.TypedSlices = false
+			plain, _ := ParseJSONWithOptions(tt.input, untyped)
+			if out, want := compactString(got), compactString(plain); out != want {
+				t.Errorf("printed %s, want %s", out, want)
+			}
+		})
+	}
+}
+
+func TestTypedSlicesAcrossAPIs(t *testing.T) {
+	const src = `{"nums": [3, 1, 2], "tags": ["b", "a"], "ids": [9007199254740993, 1], "mixed": [1, "x"]}`
+	tests := []struct {
+		name string
+		run  func(doc interface{}) (interface{}, error)
+	}{
+		{"Get", func(doc interface{}) (interface{}, error) { return Get(doc, "/nums/1") }},
+		{"Get integer", func(doc interface{}) (interface{}, error) { return Get(doc, "/ids/0") }},
+		{"GetPath", func(doc interface{}) (interface{}, error) { return GetPath(doc, "tags[1]") }},
+		{"Set", func(doc interface{}) (interface{}, error) { return Set(doc, "nums[3]", 4.0) }},
+		{"Set replaces", func(doc interface{}) (interface{}, error) { return Set(doc, "tags[0]", "z") }},
+		{"Equal", func(doc interface{}) (interface{}, error) {
+			return Equal(doc, mustParse(t, src)), nil
+		}},
+		{"CreatePatch", func(doc interface{}) (interface{}, error) {
+			return CreatePatch(doc, mustParse(t, `{"nums": [3, 2], "tags": ["b", "a", "c"], "ids": [9007199254740993, 1], "mixed": [1, "x"]}`))
+		}},
+		{"ApplyPatch", func(doc interface{}) (interface{}, error) {
+			return ApplyPatch(doc, mustParse(t, `[
+				{"op": "replace", "path": "/nums/0", "value": 7},
+				{"op": "remove", "path": "/tags/0"},
+				{"op": "add", "path": "/ids/-", "value": 5},
+				{"op": "test", "path": "/nums", "value": [7, 1, 2]}
+			]`).([]interface{}))
+		}},
+		{"SortArrays", func(doc interface{}) (interface{}, error) { return SortArrays(doc), nil }},
+		{"MapLeaves", func(doc interface{}) (interface{}, error) {
+			return MapLeaves(doc, "string", func(v interface{}) interface{} { return strings.ToUpper(v.(string)) }), nil
+		}},
+		{"MatchTemplate", func(doc interface{}) (interface{}, error) {
+			ok, mismatches := MatchTemplate(doc, mustParse(t, `{"nums": [3, 1, "<number>"], "tags": "*", "ids": ["<number>", 2], "mixed": "*"}`))
+			return []interface{}{ok, mismatches}, nil
+		}},
+		{"GenerateStruct", func(doc interface{}) (interface{}, error) { return GenerateStruct(doc, "Doc") }},
+	}
+	opts := ParseOptions{PreserveIntegers: true}
+	typedOpts := ParseOptions{PreserveIntegers: true, TypedSlices: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, _ := ParseJSONWithOptions(src, opts)
+			typed, _ := ParseJSONWithOptions(src, typedOpts)
+			want, wantErr := tt.run(plain)
+			got, err := tt.run(typed)
+			if (err != nil) != (wantErr != nil) {
+				t.Fatalf("error %v, want %v", err, wantErr)
+			}
+			if g, w := compactString(got), compactString(want); g != w {
+				t.Errorf("on typed slices got %s, want %s", g, w)
+			}
+		})
+	}
+}
+
+// The pretty-print benchmarks report B/op: PrettyPrint holds the whole output
+// in memory, while StreamPretty only needs its write buffer.
+func BenchmarkPrettyPrint(b *testing.B) {
+	v, err := ParseJSON(wideDocument(20000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		io.WriteString(io.Discard, PrettyPrint(v))
+	}
+}
+
+func BenchmarkStreamPretty(b *testing.B) {
+	v, err := ParseJSON(wideDocument(20000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := StreamPretty(io.Discard, v, "  "); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestObjectSizes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[int]int
+	}{
+		{"small objects are left out", `{"a":1,"b":2}`, nil},
+		{"large object", `{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":8,"i":9,"j":10}`, map[int]int{0: 10}},
+		{"nested members are not counted", `{"a":{"x":1,"y":2},"b":[{"z":3}],"c":1,"d":1,"e":1,"f":1,"g":1,"h":1}`, map[int]int{0: 8}},
+		{"objects in an array", wideObjects(2, 8), map[int]int{1: 8, 35: 8}},
+		{"unbalanced brackets", `]{"a":1}}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, _ := TokenizeRecover(tt.input)
+			if got := objectSizes(tokens); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("objectSizes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     interface{}
+		wantN    int
+		wantRest string
+		wantErr  string
+	}{
+		{"empty object then trailing data", `{}trailing`, map[string]interface{}{}, 2, "trailing", ""},
+		{"leading whitespace is counted", "  [1, 2] rest", []interface{}{float64(1), float64(2)}, 8, " rest", ""},
+		{"scalar", `true,false`, true, 4, ",false", ""},
+		{"number followed by a delimiter", `12 x`, float64(12), 2, " x", ""},
+		{"string with brackets inside", `"a}]"{}`, "a}]", 5, "{}", ""},
+		{"two values back to back", `{"a":1}{"b":2}`, map[string]interface{}{"a": float64(1)}, 7, `{"b":2}`, ""},
+		{"whole input", `null`, nil, 4, "", ""},
+		{"empty input", "   ", nil, 0, "", "unexpected end of input"},
+		{"unterminated value", `{"a": 1`, nil, 0, "", "end of input"},
+		{"number glued to text", `12x`, nil, 0, "", "unexpected 'x' after number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n, err := ParsePrefix(tt.input)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+			if n != tt.wantN || tt.input[n:] != tt.wantRest {
+				t.Errorf("n = %d leaving %q, want %d leaving %q", n, tt.input[n:], tt.wantN, tt.wantRest)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeUnicode(t *testing.T) {
+	const (
+		nfc = "caf\u00e9"  // é as one code point
+		nfd = "cafe\u0301" // e followed by a combining acute accent
+	)
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+		want  interface{}
+	}{
+		{"off by default", `"` + nfd + `"`, ParseOptions{}, nfd},
+		{"NFD string becomes NFC", `"` + nfd + `"`, ParseOptions{NormalizeUnicode: true}, nfc},
+		{"escaped combining mark", `"cafe\u0301"`, ParseOptions{NormalizeUnicode: true}, nfc},
+		{"NFC string is kept", `"` + nfc + `"`, ParseOptions{NormalizeUnicode: true}, nfc},
+		{"other forms", `"` + nfc + `"`, ParseOptions{NormalizeUnicode: true, UnicodeForm: norm.NFD}, nfd},
+		{"keys are normalized", `{"` + nfd + `": "` + nfd + `"}`, ParseOptions{NormalizeUnicode: true}, map[string]interface{}{nfc: nfc}},
+		{"nested strings", `[["` + nfd + `"]]`, ParseOptions{NormalizeUnicode: true}, []interface{}{[]interface{}{nfc}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+q, want %+q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisallowDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string // duplicate paths, nil for no error
+	}{
+		{"no duplicates", `{"a": 1, "b": {"a": 2}}`, nil},
+		{"same key in sibling objects", `[{"id": 1}, {"id": 2}]`, nil},
+		{"top level", `{"a": 1, "a": 2}`, []string{"/a"}},
+		{"two different levels", `{"a": 1, "b": {"c": 1, "c": 2}, "a": 3}`, []string{"/b/c", "/a"}},
+		{"inside an array", `{"list": [{"k": 1}, {"k": 1, "k": 2}]}`, []string{"/list/1/k"}},
+		{"every repeat is listed", `{"x": 1, "x": 2, "x": 3}`, []string{"/x", "/x"}},
+		{"keys are escaped", `{"a/b": {"~": 1, "~": 2}, "a/b": 0}`, []string{"/a~1b/~0", "/a~1b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONWithOptions(tt.input, ParseOptions{DisallowDuplicateKeys: true})
+			var got []string
+			if err != nil {
+				dup, ok := err.(*DuplicateKeyError)
+				if !ok {
+					t.Fatalf("got %v, want a *DuplicateKeyError", err)
+				}
+				got = dup.Paths
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("duplicate paths %q, want %q", got, tt.want)
+			}
+
+			// The streaming decoder collects them the same way.
+			_, err = NewDecoderWithOptions(strings.NewReader(tt.input), ParseOptions{DisallowDuplicateKeys: true}).Decode()
+			if dup, _ := err.(*DuplicateKeyError); (dup == nil) != (tt.want == nil) || dup != nil && !reflect.DeepEqual(dup.Paths, tt.want) {
+				t.Errorf("Decoder returned %v", err)
+			}
+
+			// Without the option the last value wins and nothing is reported.
+			var offsets []int
+			if _, err := ParseJSONWithOptions(tt.input, ParseOptions{OnDuplicateKey: func(key string, pos int) {
+				offsets = append(offsets, pos)
+			}}); err != nil {
+				t.Errorf("lenient parse failed: %v", err)
+			}
+			if len(offsets) != len(tt.want) {
+				t.Errorf("OnDuplicateKey called %d times, want %d", len(offsets), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestOnDuplicateKey(t *testing.T) {
+	type report struct {
+		key string
+		pos int
+	}
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+		want  []report
+		tree  string
+	}{
+		{"no duplicates", `{"a": 1, "b": {"a": 2}}`, ParseOptions{}, nil, `{"a": 1, "b": {"a": 2}}`},
+		{"request example", `{"a":1,"a":2}`, ParseOptions{}, []report{{"a", 7}}, `{"a": 2}`},
+		{"every repeat", `{"x": 1, "x": 2, "x": 3}`, ParseOptions{}, []report{{"x", 9}, {"x", 17}}, `{"x": 3}`},
+		{"nested object", `{"o": {"k": [], "k": {}}}`, ParseOptions{}, []report{{"k", 16}}, `{"o": {"k": {}}}`},
+		{"escaped spelling of the same key", `{"\u0061": 1, "a": 2}`, ParseOptions{}, []report{{"a", 14}}, `{"a": 2}`},
+		{"byte offset after multibyte text", `{"é": 1, "é": 2}`, ParseOptions{}, []report{{"é", 10}}, `{"é": 2}`},
+		{"after a comment", "{\"a\": 1, /* again */ \"a\": 2}", ParseOptions{AllowComments: true}, []report{{"a", 21}}, `{"a": 2}`},
+		{"identifier keys", `{a: 1, a: 2}`, JSON5Options(), []report{{"a", 7}}, `{"a": 2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []report
+			opts := tt.opts
+			opts.OnDuplicateKey = func(key string, pos int) {
+				got = append(got, report{key, pos})
+			}
+			tree, err := ParseJSONWithOptions(tt.input, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reported %v, want %v", got, tt.want)
+			}
+			for _, r := range got {
+				if key := tt.input[r.pos:]; !strings.HasPrefix(key, `"`+r.key) && !strings.HasPrefix(key, r.key) {
+					t.Errorf("offset %d points at %.10q, not key %q", r.pos, key, r.key)
+				}
+			}
+			if !Equal(tree, mustParse(t, tt.tree)) {
+				t.Errorf("tree = %v, want %s", tree, tt.tree)
+			}
+
+			// With DisallowDuplicateKeys as well, the callback still sees every repeat.
+			got = nil
+			opts.DisallowDuplicateKeys = true
+			_, err = ParseJSONWithOptions(tt.input, opts)
+			if (err != nil) != (tt.want != nil) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("with DisallowDuplicateKeys: reported %v, err %v", got, err)
+			}
+		})
+	}
+}
+
+func TestPrettyPrintCanonicalDisplay(t *testing.T) {
+	// 72 columns: inline after `  "k": ` it ends at column 79, after `  "key": ` at 81
+	long := "[" + strings.TrimSuffix(strings.Repeat(`"abcdefgh", `, 6), ", ") + "]"
+	// str(n) is a string n+2 columns wide
+	str := func(n int) string { return `"` + strings.Repeat("x", n) + `"` }
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"scalar root", `1.5`, "1.5\n"},
+		{"keys sorted, two-space indent", `{"b": 1, "a": {"d": null, "c": "x"}}`,
+			"{\n  \"a\": {\n    \"c\": \"x\",\n    \"d\": null\n  },\n  \"b\": 1\n}\n"},
+		{"scalar arrays inline", `{"tags": ["x", "y"], "n": [1, 2, 3]}`,
+			"{\n  \"n\": [1, 2, 3],\n  \"tags\": [\"x\", \"y\"]\n}\n"},
+		{"arrays of objects stay expanded", `[{"a": 1}]`, "[\n  {\n    \"a\": 1\n  }\n]\n"},
+		{"inline array that fits in the width", `{"k": ` + long + `}`,
+			"{\n  \"k\": " + long + "\n}\n"},
+		{"too wide to inline", `{"key": ` + long + `}`,
+			"{\n  \"key\": [\n" + strings.Repeat("    \"abcdefgh\",\n", 5) + "    \"abcdefgh\"\n  ]\n}\n"},
+		{"member and its comma end at column 80", `{"a": [` + str(68) + `], "b": 1}`,
+			"{\n  \"a\": [" + str(68) + "],\n  \"b\": 1\n}\n"},
+		{"comma after the member would end at column 81", `{"a": [` + str(69) + `], "b": 1}`,
+			"{\n  \"a\": [\n    " + str(69) + "\n  ],\n  \"b\": 1\n}\n"},
+		{"last member ends at column 80", `{"b": 1, "c": [` + str(69) + `]}`,
+			"{\n  \"b\": 1,\n  \"c\": [" + str(69) + "]\n}\n"},
+		{"last member would end at column 81", `{"b": 1, "c": [` + str(70) + `]}`,
+			"{\n  \"b\": 1,\n  \"c\": [\n    " + str(70) + "\n  ]\n}\n"},
+		{"element and its comma end at column 80", `[[` + str(73) + `], 1]`,
+			"[\n  [" + str(73) + "],\n  1\n]\n"},
+		{"comma after the element would end at column 81", `[[` + str(74) + `], 1]`,
+			"[\n  [\n    " + str(74) + "\n  ],\n  1\n]\n"},
+		{"empty object root", `{}`, "{}\n"},
+		{"empty array root", `[ ]`, "[]\n"},
+		{"empty members", `{"b": {}, "a": []}`, "{\n  \"a\": [],\n  \"b\": {}\n}\n"},
+		{"empty containers nested in arrays", `[{}, [[]], {"c": {}}]`,
+			"[\n  {},\n  [\n    []\n  ],\n  {\n    \"c\": {}\n  }\n]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrettyPrintCanonicalDisplay(mustParse(t, tt.input)); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettyPrintInlineScalarArrays(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"numbers inline", `[1, 2, 3]`, "[1, 2, 3]"},
+		{"mixed scalars inline", `{"a": [1, "x", true, null]}`, "{\n  \"a\": [1, \"x\", true, null]\n}"},
+		{"objects broken out", `[{"a": 1}, {"b": [2, 3]}]`,
+			"[\n  {\n    \"a\": 1\n  },\n  {\n    \"b\": [2, 3]\n  }\n]"},
+		{"a nested array breaks its parent out", `[1, [2, 3]]`, "[\n  1,\n  [2, 3]\n]"},
+		{"no width limit", `[` + strings.Repeat(`1234567890, `, 20) + `0]`, "[" + strings.Repeat("1234567890, ", 20) + "0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, tt.input)
+			if got := PrettyPrintWithOptions(v, PrettyOptions{InlineScalarArrays: true}); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			if got, want := PrettyPrintWithOptions(v, PrettyOptions{}), PrettyPrint(v); got != want {
+				t.Errorf("without InlineScalarArrays got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestPrettyPrintEmptyContainers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty object", `{}`, "{}"},
+		{"empty array", `[]`, "[]"},
+		{"empty object member", `{"a": {}}`, "{\n  \"a\": {}\n}"},
+		{"empty array member", `{"a": []}`, "{\n  \"a\": []\n}"},
+		{"empty array element", `[[], 1]`, "[\n  [],\n  1\n]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, tt.input)
+			if got := PrettyPrint(v); got != tt.want {
+				t.Errorf("PrettyPrint = %q, want %q", got, tt.want)
+			}
+			if got := PrettyPrintWithOptions(v, PrettyOptions{InlineScalarArrays: true}); got != tt.want {
+				t.Errorf("PrettyPrintWithOptions = %q, want %q", got, tt.want)
+			}
+			var sb strings.Builder
+			if err := StreamPretty(&sb, v, "  "); err != nil || sb.String() != tt.want {
+				t.Errorf("StreamPretty = %q, %v; want %q", sb.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettyPrintln(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"scalar", `42`, "42"},
+		{"string", `"a\nb"`, `"a\nb"`},
+		{"empty object", `{}`, "{}"},
+		{"nested", `{"a": [1, {"b": null}]}`, "{\n  \"a\": [\n    1,\n    {\n      \"b\": null\n    }\n  ]\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, tt.input)
+			if got := PrettyPrint(v); got != tt.want {
+				t.Errorf("PrettyPrint = %q, want %q", got, tt.want)
+			}
+			if got := PrettyPrintln(v); got != tt.want+"\n" {
+				t.Errorf("PrettyPrintln = %q, want %q", got, tt.want+"\n")
+			}
+		})
+	}
+}
+
+func TestPrettyPrintCanonicalDisplayIsStable(t *testing.T) {
+	orders := []string{
+		`{"z": [3, 1], "a": {"y": true, "b": null}, "m": [{"q": 1, "p": 2}]}`,
+		`{"m": [{"p": 2, "q": 1}], "a": {"b": null, "y": true}, "z": [3, 1]}`,
+		"{\n\"a\":{\"y\":true,\"b\":null},\n\"z\":[3,1],\"m\":[{\"q\":1,\"p\":2}]}",
+	}
+	want := PrettyPrintCanonicalDisplay(mustParse(t, orders[0]))
+	for i, doc := range orders {
+		for run := 0; run < 20; run++ {
+			if got := PrettyPrintCanonicalDisplay(mustParse(t, doc)); got != want {
+				t.Fatalf("order %d, run %d:\n%s\nwant\n%s", i, run, got, want)
+			}
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("[", depth) + strings.Repeat("]", depth)
+	}
+	tests := []struct {
+		name    string
+		input   string
+		limit   int
+		wantErr string
+	}{
+		{"hostile input fails cleanly", strings.Repeat("[", 100000), 0,
+			"maximum nesting depth exceeded at line 1, col " + strconv.Itoa(defaultMaxDepth+1)},
+		{"hostile objects fail cleanly", strings.Repeat(`{"a":`, 100000), 0,
+			"maximum nesting depth exceeded at line 1, col " + strconv.Itoa(5*defaultMaxDepth+1)},
+		{"default limit reached", nested(defaultMaxDepth), 0, ""},
+		{"default limit exceeded", nested(defaultMaxDepth + 1), 0, "maximum nesting depth exceeded"},
+		{"custom limit reached", `{"a": [{"b": 1}]}`, 3, ""},
+		{"custom limit exceeded", `{"a": [{"b": []}]}`, 3, "maximum nesting depth exceeded at line 1, col 14"},
+		{"line of the offending bracket", "[\n [\n  [\n   1]]]", 2, "maximum nesting depth exceeded at line 3, col 3"},
+		{"siblings don't add up", `[[1], [2], [3], [4]]`, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ParseOptions{MaxDepth: tt.limit}
+			_, err := ParseJSONWithOptions(tt.input, opts)
+			checkErr(t, err, tt.wantErr)
+
+			// The streaming decoder shares the parser and its limit.
+			_, err = NewDecoderWithOptions(strings.NewReader(tt.input), opts).Decode()
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCompactMaxDepth(t *testing.T) {
+	_, err := Compact([]byte(strings.Repeat("[", 100000) + strings.Repeat("]", 100000)))
+	checkErr(t, err, "maximum nesting depth exceeded at line 1, col "+strconv.Itoa(defaultMaxDepth+1))
+}
+
+func TestTrailingDataOffset(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantOffset int
+		wantMsg    string
+	}{
+		{"garbage after an array", `[1,2,3] garbage`, 8, `extra tokens after value at line 1, col 9 (offset 8): "garbage"`},
+		{"second document", `{"a":1}{"b":2}`, 7, `at line 1, col 8 (offset 7): "{\"b\":2}"`},
+		{"on a later line", "[1]\n\n  x", 7, `at line 3, col 3 (offset 7): "x"`},
+		{"snippet stops at the line end", "1 2\n3", 2, `(offset 2): "2"`},
+		{"long snippet is cut", `{} ` + strings.Repeat("z", 30), 3, `(offset 3): "` + strings.Repeat("z", 20) + `..."`},
+		{"comment before the data", "[] /* c */ 5", 11, `(offset 11): "5"`},
+		{"multibyte characters count as one column", `"é" x`, 5, `at line 1, col 5 (offset 5): "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONWithOptions(tt.input, ParseOptions{AllowComments: true})
+			var syntax *SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("got %v, want a *SyntaxError", err)
+			}
+			if syntax.Offset != tt.wantOffset {
+				t.Errorf("offset = %d, want %d", syntax.Offset, tt.wantOffset)
+			}
+			checkErr(t, err, tt.wantMsg)
+			if _, err := ParseJSONWithOptions(tt.input[:syntax.Offset], ParseOptions{AllowComments: true}); err != nil {
+				t.Errorf("the input before the offset doesn't parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestTolerantNumbers(t *testing.T) {
+	tolerant := ParseOptions{AllowPlusSign: true, AllowLooseDecimals: true, AllowLeadingZeros: true}
+	tests := []struct {
+		name      string
+		input     string
+		want      float64
+		strictErr string
+	}{
+		{"leading plus", `+5`, 5, "unexpected character at line 1, col 1: +"},
+		{"leading zero", `05`, 5, "leading zero in integer part"},
+		{"trailing decimal point", `5.`, 5, "missing digits"},
+		{"leading decimal point", `.5`, 0.5, "unexpected character at line 1, col 1: ."},
+		{"several leading zeros", `007`, 7, "leading zero in integer part"},
+		{"only zeros", `000`, 0, "leading zero in integer part"},
+		{"negative with leading zeros", `-007`, -7, "leading zero in integer part"},
+		{"leading zeros before a fraction", `007.25`, 7.25, "leading zero in integer part"},
+		{"leading zeros before an exponent", `05e2`, 500, "leading zero in integer part"},
+		{"plus, zeros and a trailing point", `+05.`, 5, "unexpected character at line 1, col 1: +"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONWithOptions(tt.input, tolerant)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("tolerant parse = %#v, want %v", got, tt.want)
+			}
+			withInts := tolerant
+			withInts.PreserveIntegers = true
+			if got, err := ParseJSONWithOptions(tt.input, withInts); err != nil || !Equal(got, tt.want) {
+				t.Errorf("tolerant parse with PreserveIntegers = %#v, %v; want %v", got, err, tt.want)
+			}
+			_, err = ParseJSON(tt.input)
+			checkErr(t, err, tt.strictErr)
+		})
+	}
+}
+
+func TestValidateNumber(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{"0", ""},
+		{"-0", ""},
+		{"123", ""},
+		{"-1.5e-3", ""},
+		{"2E+10", ""},
+		{"", "missing digits in integer part"},
+		{"-", "missing digits in integer part"},
+		{"+1", "missing digits in integer part"},
+		{"007", "leading zero in integer part"},
+		{"1.", "missing digits after '.'"},
+		{".5", "missing digits in integer part"},
+		{"1e", "missing digits in exponent"},
+		{"NaN", "missing digits in integer part"},
+		{"Inf", "missing digits in integer part"},
+		{"-infinity", "missing digits in integer part"},
+		{"0x1p-2", "unexpected 'x' after number"},
+		{"1_000", "unexpected '_' after number"},
+		{" 1", "missing digits in integer part"},
+		{"1 ", "unexpected ' ' after number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			checkErr(t, ValidateNumber(tt.input), tt.wantErr)
+		})
+	}
+}
+
+func TestTrailingCommas(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		want       interface{}
+		lenientErr string
+		strictErr  string
+	}{
+		{"after the last member", `{"a":1,}`, map[string]interface{}{"a": 1.0}, "", "trailing comma before '}' at line 1, col 8"},
+		{"after the last element", `[1,]`, []interface{}{1.0}, "", "trailing comma before ']' at line 1, col 4"},
+		{"nested, with whitespace", "{\"a\": [1, 2 ,\n] ,\n}", map[string]interface{}{"a": []interface{}{1.0, 2.0}}, "", "trailing comma before ']' at line 2, col 1"},
+		{"doubled comma between elements", `[1,,2]`, nil, "missing value before ',' at line 1, col 4", "missing value before ',' at line 1, col 4"},
+		{"doubled trailing comma", `[1,,]`, nil, "missing value before ',' at line 1, col 4", "missing value before ',' at line 1, col 4"},
+		{"leading comma", `[,1]`, nil, "missing value before ',' at line 1, col 2", "missing value before ',' at line 1, col 2"},
+		{"only a comma", `[,]`, nil, "missing value before ',' at line 1, col 2", "missing value before ',' at line 1, col 2"},
+		{"doubled comma between members", `{"a":1,,"b":2}`, nil, "missing member before ',' at line 1, col 8", "missing member before ',' at line 1, col 8"},
+		{"leading comma in an object", `{,"a":1}`, nil, "missing member before ',' at line 1, col 2", "missing member before ',' at line 1, col 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONWithOptions(tt.input, ParseOptions{AllowTrailingCommas: true})
+			checkErr(t, err, tt.lenientErr)
+			if tt.lenientErr == "" && !Equal(got, tt.want) {
+				t.Errorf("lenient parse = %v, want %v", got, tt.want)
+			}
+			_, err = ParseJSON(tt.input)
+			checkErr(t, err, tt.strictErr)
+		})
+	}
+}
+
+func TestHexNumbers(t *testing.T) {
+	hex := ParseOptions{AllowHexNumbers: true}
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr string
+	}{
+		{"upper case digits", `0xFF`, 255.0, ""},
+		{"upper case prefix, lower case digits", `0Xff`, 255.0, ""},
+		{"negative", `-0x10`, -16.0, ""},
+		{"zero", `0x0`, 0.0, ""},
+		{"in an array", `[0xA, 0xb]`, []interface{}{10.0, 11.0}, ""},
+		{"object member", `{"mask": 0x1F}`, map[string]interface{}{"mask": 31.0}, ""},
+		{"stays a float with PreserveIntegers", `0x7B`, 123.0, ""},
+		{"no digits", `0x`, nil, `invalid hex number "0x" at line 1, col 1`},
+		{"more than 64 bits", `0x1FFFFFFFFFFFFFFFF`, nil, `invalid hex number "0x1FFFFFFFFFFFFFFFF" at line 1, col 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withInts := hex
+			withInts.PreserveIntegers = true
+			for _, opts := range []ParseOptions{hex, withInts, JSON5Options()} {
+				got, err := ParseJSONWithOptions(tt.input, opts)
+				checkErr(t, err, tt.wantErr)
+				if tt.wantErr == "" && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ParseJSONWithOptions(%s) = %#v, want %#v", tt.input, got, tt.want)
+				}
+			}
+			_, err := ParseJSON(tt.input)
+			checkErr(t, err, "invalid number")
+		})
+	}
+}
+
+func TestAllowUndefined(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      interface{}
+		strictErr string
+	}{
+		{"object member", `{"a": undefined}`, map[string]interface{}{"a": nil}, "unexpected character at line 1, col 7: u"},
+		{"array element", `[1, undefined]`, []interface{}{1.0, nil}, "unexpected character at line 1, col 5: u"},
+		{"root", `undefined`, nil, "unexpected character at line 1, col 1: u"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, opts := range []ParseOptions{LenientOptions(), {AllowUndefined: true}} {
+				got, err := ParseJSONWithOptions(tt.input, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !Equal(got, tt.want) {
+					t.Errorf("lenient parse = %#v, want %#v", got, tt.want)
+				}
+			}
+			_, err := ParseJSON(tt.input)
+			checkErr(t, err, tt.strictErr)
+		})
+	}
+}
+
+func TestPrettyPrintMarked(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		pointer string
+		want    string
+	}{
+		{"root scalar", `5`, "", ">>>5<<<"},
+		{"root object", `{"a": 1}`, "", ">>>{\n  \"a\": 1\n}<<<"},
+		{"nested member", `{"a": {"b": true}}`, "/a/b", "{\n  \"a\": {\n    \"b\": >>>true<<<\n  }\n}"},
+		{"container member", `{"a": [1, 2]}`, "/a", "{\n  \"a\": >>>[\n    1,\n    2\n  ]<<<\n}"},
+		{"array element", `[1, [2, 3]]`, "/1/0", "[\n  1,\n  [\n    >>>2<<<,\n    3\n  ]\n]"},
+		{"only the addressed element", `[[1], [1]]`, "/1/0", "[\n  [\n    1\n  ],\n  [\n    >>>1<<<\n  ]\n]"},
+		{"escaped key", `{"a/b": {"~": null}}`, "/a~1b/~0", "{\n  \"a/b\": {\n    \"~\": >>>null<<<\n  }\n}"},
+		{"missing key", `{"a": 1}`, "/b", "{\n  \"a\": 1\n}"},
+		{"key prefix is not a match", `{"ab": 1}`, "/a", "{\n  \"ab\": 1\n}"},
+		{"index out of range", `{"a": [1]}`, "/a/5", "{\n  \"a\": [\n    1\n  ]\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrettyPrintMarked(mustParse(t, tt.input), tt.pointer, ">>>", "<<<"); got != tt.want {
+				t.Errorf("PrettyPrintMarked(%s, %q) =\n%s\nwant\n%s", tt.input, tt.pointer, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettyPrintHighlight(t *testing.T) {
+	// Sibling order is random, so check the highlighted span and that the rest is the unmarked document.
+	doc := mustParse(t, `{"x": 1, "y": {"z": "hi", "w": 2}, "v": [3, 4]}`)
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{"/y/z", `"z": ` + "\x1b[7m" + `"hi"` + "\x1b[0m"},
+		{"/v/1", "\x1b[7m4\x1b[0m"},
+		{"/x", `"x": ` + "\x1b[7m1\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			got := PrettyPrintHighlight(doc, tt.pointer)
+			if n := strings.Count(got, "\x1b[7m"); n != 1 || !strings.Contains(got, tt.want) {
+				t.Errorf("PrettyPrintHighlight(%q) has %d highlights, want one around %q:\n%s", tt.pointer, n, tt.want, got)
+			}
+			plain := strings.NewReplacer("\x1b[7m", "", "\x1b[0m", "").Replace(got)
+			back, err := ParseJSON(plain)
+			if err != nil || !Equal(back, doc) {
+				t.Errorf("without highlighting, output doesn't read back as the document: %v\n%s", err, plain)
+			}
+		})
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		in   float64
+		want string
+	}{
+		{"zero", 0, "0"},
+		{"negative zero", math.Copysign(0, -1), "-0"},
+		{"small integer", 42, "42"},
+		{"negative integer", -7, "-7"},
+		{"million has no exponent", 1e6, "1000000"},
+		{"largest safe integer", 1 << 53, "9007199254740992"},
+		{"negative largest safe integer", -(1 << 53), "-9007199254740992"},
+		{"beyond safe integers", 1 << 54, "1.8014398509481984e+16"},
+		{"large exponent", 1e21, "1e+21"},
+		{"one tenth", 0.1, "0.1"},
+		{"float sum that drifts", 0.30000000000000004, "0.30000000000000004"},
+		{"fraction", -123.456, "-123.456"},
+		{"small exponent", 1.5e-7, "1.5e-07"},
+		{"smallest float", math.SmallestNonzeroFloat64, "5e-324"},
+		{"largest float", math.MaxFloat64, "1.7976931348623157e+308"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatNumber(tt.in)
+			if got != tt.want {
+				t.Errorf("FormatNumber(%v) = %s, want %s", tt.in, got, tt.want)
+			}
+			if tt.in != math.Trunc(tt.in) || math.Abs(tt.in) > 1<<53 {
+				if g := strconv.FormatFloat(tt.in, 'g', -1, 64); got != g {
+					t.Errorf("FormatNumber(%v) = %s, but FormatFloat gives %s", tt.in, got, g)
+				}
+			} else if strings.ContainsAny(got, ".e") {
+				t.Errorf("integral value written as %s", got)
+			}
+			if back, err := strconv.ParseFloat(got, 64); err != nil || back != tt.in {
+				t.Errorf("%s parses back as %v, %v", got, back, err)
+			}
+			// Every printer writes numbers the same way.
+			if out := PrettyPrint(tt.in); out != got {
+				t.Errorf("PrettyPrint = %s", out)
+			}
+			if out, err := Marshal(tt.in); err != nil || string(out) != got {
+				t.Errorf("Marshal = %s, %v", out, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,543 @@
+package jsonparser
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// / maxLCSCells bounds the table used to align arrays; longer arrays fall back to index-by-index diffs.
+const maxLCSCells = 1 << 20
+
+// /**
+// * @brief Reports whether two parsed JSON values are deeply equal.
+// *
+// * @details Numbers are compared by value, so the int64 1 from ParseOptions.PreserveIntegers equals the
+// * float64 1.
+// *
+// * @param a The first value.
+// * @param b The second value.
+// * @return True if both values have the same type and contents.
+// */
+func Equal(a, b interface{}) bool {
+	a, b = untypedSlice(a), untypedSlice(b)
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, val := range av {
+			other, ok := bv[k]
+			if !ok || !Equal(val, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !Equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case float64:
+		switch bv := b.(type) {
+		case float64:
+			return av == bv
+		case int64:
+			return integerEquals(bv, av)
+		}
+		return false
+	case int64:
+		switch bv := b.(type) {
+		case int64:
+			return av == bv
+		case float64:
+			return integerEquals(av, bv)
+		}
+		return false
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case nil:
+		return b == nil
+	}
+	return false
+}
+
+// /**
+// * @brief Reports whether an int64 and a float64 are the same number, without rounding the int64.
+// *
+// * @param i The integer.
+// * @param f The float.
+// * @return True if f is a whole number in the int64 range equal to i.
+// */
+func integerEquals(i int64, f float64) bool {
+	return f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 && int64(f) == i
+}
+
+// /**
+// * @brief Creates an RFC 6902 JSON Patch that transforms a into b.
+// *
+// * @details Objects are compared member by member in sorted key order. Arrays are aligned on their
+// * longest common subsequence so that inserting or removing one element produces a single operation
+// * rather than a rewrite of the tail. Each operation is a map with "op", "path" and (for add and
+// * replace) "value" members, ready to be serialized.
+// *
+// * @param a The source document.
+// * @param b The target document.
+// * @return The patch operations or an error for values that aren't JSON.
+// */
+func CreatePatch(a, b interface{}) ([]interface{}, error) {
+	var ops []interface{}
+	if err := diffValues("", a, b, &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// /**
+// * @brief Builds one patch operation.
+// *
+// * @param op The operation name ("add", "remove" or "replace").
+// * @param path The JSON Pointer the operation targets.
+// * @param value The new value, ignored for "remove".
+// * @return The operation as a JSON object.
+// */
+func patchOp(op, path string, value interface{}) map[string]interface{} {
+	o := map[string]interface{}{"op": op, "path": path}
+	if op != "remove" {
+		o["value"] = value
+	}
+	return o
+}
+
+// /**
+// * @brief Appends the operations turning a into b at path.
+// *
+// * @param path The JSON Pointer of the values being compared.
+// * @param a The source value.
+// * @param b The target value.
+// * @param ops The operation list to append to.
+// * @return An error if either value isn't a JSON value.
+// */
+func diffValues(path string, a, b interface{}, ops *[]interface{}) error {
+	a, b = untypedSlice(a), untypedSlice(b)
+	for _, v := range []interface{}{a, b} {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}, string, float64, int64, bool, nil:
+		default:
+			return fmt.Errorf("unsupported type %T at %q", v, path)
+		}
+	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			return diffObjects(path, av, bv, ops)
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			return diffArrays(path, av, bv, ops)
+		}
+	}
+	if !Equal(a, b) {
+		*ops = append(*ops, patchOp("replace", path, b))
+	}
+	return nil
+}
+
+// /**
+// * @brief Appends the operations turning object a into object b.
+// *
+// * @details Removed keys come first, then changed and added keys, each in sorted order.
+// */
+func diffObjects(path string, a, b map[string]interface{}, ops *[]interface{}) error {
+	var removed, kept []string
+	for k := range a {
+		if _, ok := b[k]; ok {
+			kept = append(kept, k)
+		} else {
+			removed = append(removed, k)
+		}
+	}
+	var added []string
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			added = append(added, k)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(kept)
+	sort.Strings(added)
+
+	for _, k := range removed {
+		*ops = append(*ops, patchOp("remove", path+"/"+escapePointerToken(k), nil))
+	}
+	for _, k := range kept {
+		if err := diffValues(path+"/"+escapePointerToken(k), a[k], b[k], ops); err != nil {
+			return err
+		}
+	}
+	for _, k := range added {
+		*ops = append(*ops, patchOp("add", path+"/"+escapePointerToken(k), b[k]))
+	}
+	return nil
+}
+
+// /**
+// * @brief Appends the operations turning array a into array b.
+// *
+// * @details Elements on the longest common subsequence stay put. Around them, an unmatched pair is
+// * diffed in place, leftover source elements are removed and leftover target elements are added.
+// * Indices in the emitted paths account for the operations already applied.
+// */
+func diffArrays(path string, a, b []interface{}, ops *[]interface{}) error {
+	/// Equal prefixes and suffixes never need an operation; trim them before aligning.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && Equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && Equal(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	matches := lcsMatches(midA, midB)
+	i, j, pos := 0, 0, prefix
+	for _, m := range append(matches, [2]int{len(midA), len(midB)}) {
+		/// Pair up unmatched elements before the next match and diff them in place.
+		for i < m[0] && j < m[1] {
+			if err := diffValues(path+"/"+strconv.Itoa(pos), midA[i], midB[j], ops); err != nil {
+				return err
+			}
+			i, j, pos = i+1, j+1, pos+1
+		}
+		for ; i < m[0]; i++ {
+			*ops = append(*ops, patchOp("remove", path+"/"+strconv.Itoa(pos), nil))
+		}
+		for ; j < m[1]; j++ {
+			*ops = append(*ops, patchOp("add", path+"/"+strconv.Itoa(pos), midB[j]))
+			pos++
+		}
+		/// Step over the matched element itself.
+		i, j, pos = i+1, j+1, pos+1
+	}
+	return nil
+}
+
+// /**
+// * @brief Finds the index pairs of a longest common subsequence of two arrays.
+// *
+// * @details Arrays whose table would exceed maxLCSCells get no matches, which degrades to
+// * index-by-index diffing.
+// *
+// * @return The matched (index in a, index in b) pairs in increasing order.
+// */
+func lcsMatches(a, b []interface{}) [][2]int {
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxLCSCells {
+		return nil
+	}
+	/// table[i][j] is the LCS length of a[i:] and b[j:].
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if Equal(a[i], b[j]) {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+	var matches [][2]int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case Equal(a[i], b[j]):
+			matches = append(matches, [2]int{i, j})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+// /**
+// * @brief Applies an RFC 6902 JSON Patch to a document.
+// *
+// * @details Supports the "add", "remove", "replace", "move", "copy" and "test" operations. The patch
+// * is applied to a Clone of doc, so doc itself is never modified and a patch that fails partway leaves
+// * nothing half-applied. As RFC 6902 requires, a value can't be moved into one of its own children.
+// *
+// * @param doc The document to patch.
+// * @param patch The operations, as produced by CreatePatch or parsed from JSON.
+// * @return The patched document or an error naming the failing operation.
+// */
+func ApplyPatch(doc interface{}, patch []interface{}) (interface{}, error) {
+	doc = Clone(doc)
+	for i, raw := range patch {
+		op, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("patch operation %d is not an object", i)
+		}
+		name, _ := op["op"].(string)
+		path, ok := op["path"].(string)
+		if !ok {
+			return nil, fmt.Errorf("patch operation %d: missing \"path\"", i)
+		}
+		var err error
+		switch name {
+		case "add", "replace", "test":
+			value, ok := op["value"]
+			if !ok {
+				return nil, fmt.Errorf("patch operation %d: missing \"value\"", i)
+			}
+			doc, err = applyOp(doc, name, path, Clone(value))
+		case "remove":
+			doc, err = applyOp(doc, name, path, nil)
+		case "move", "copy":
+			from, ok := op["from"].(string)
+			if !ok {
+				return nil, fmt.Errorf("patch operation %d: missing \"from\"", i)
+			}
+			if name == "move" && strings.HasPrefix(path, from+"/") {
+				return nil, fmt.Errorf("patch operation %d: cannot move %q into its own child %q", i, from, path)
+			}
+			var value interface{}
+			if value, err = Get(doc, from); err == nil {
+				if name == "copy" {
+					value = Clone(value)
+				}
+				if name == "move" {
+					doc, err = applyOp(doc, "remove", from, nil)
+				}
+				if err == nil {
+					doc, err = applyOp(doc, "add", path, value)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("patch operation %d: unknown op %q", i, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %v", i, name, path, err)
+		}
+	}
+	return doc, nil
+}
+
+// /**
+// * @brief Applies a single add, remove, replace or test operation.
+// *
+// * @return The (possibly new) document or an error.
+// */
+func applyOp(doc interface{}, op, path string, value interface{}) (interface{}, error) {
+	refs, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 {
+		switch op {
+		case "remove":
+			return nil, nil
+		case "test":
+			if !Equal(doc, value) {
+				return nil, fmt.Errorf("test failed")
+			}
+			return doc, nil
+		}
+		return value, nil
+	}
+	parentPath := path[:len(path)-len(escapePointerToken(refs[len(refs)-1]))-1]
+	parent, err := Get(doc, parentPath)
+	if err != nil {
+		return nil, err
+	}
+	last := refs[len(refs)-1]
+
+	switch p := untypedSlice(parent).(type) {
+	case map[string]interface{}:
+		current, exists := p[last]
+		switch op {
+		case "add":
+			p[last] = value
+		case "replace", "remove":
+			if !exists {
+				return nil, fmt.Errorf("key %q not found", last)
+			}
+			if op == "remove" {
+				delete(p, last)
+			} else {
+				p[last] = value
+			}
+		case "test":
+			if !exists || !Equal(current, value) {
+				return nil, fmt.Errorf("test failed")
+			}
+		}
+		return doc, nil
+	case []interface{}:
+		var index int
+		if op == "add" && last == "-" {
+			index = len(p)
+		} else if op == "add" {
+			index, err = pointerIndex(last, len(p)+1)
+		} else {
+			index, err = pointerIndex(last, len(p))
+		}
+		if err != nil {
+			return nil, err
+		}
+		var updated []interface{}
+		switch op {
+		case "add":
+			updated = append(updated, p[:index]...)
+			updated = append(updated, value)
+			updated = append(updated, p[index:]...)
+		case "remove":
+			updated = append(updated, p[:index]...)
+			updated = append(updated, p[index+1:]...)
+		case "replace":
+			p[index] = value
+			if _, boxed := parent.([]interface{}); boxed {
+				return doc, nil
+			}
+			/// p is a boxed copy of a typed slice, so it takes the slice's place.
+			updated = p
+		case "test":
+			if !Equal(p[index], value) {
+				return nil, fmt.Errorf("test failed")
+			}
+			return doc, nil
+		}
+		/// The array changed length or was boxed, so store the new slice in its parent.
+		return applyOp(doc, "replace", parentPath, updated)
+	}
+	return nil, fmt.Errorf("cannot descend into scalar at %q", parentPath)
+}
+
+// / Operation is one add, remove or replace step of a patch stream.
+type Operation struct {
+	Op    string      ///< "add", "remove" or "replace"
+	Path  string      ///< JSON Pointer of the target, valid once the earlier operations are applied
+	Value interface{} ///< the new value; unused for "remove"
+}
+
+// /**
+// * @brief Encodes the operation as an RFC 6902 patch object, without "value" for a remove.
+// *
+// * @return The JSON text, e.g. {"op":"replace","path":"/a","value":1}.
+// */
+func (o Operation) MarshalJSON() ([]byte, error) {
+	return Marshal(patchOp(o.Op, o.Path, o.Value))
+}
+
+// /**
+// * @brief Computes the operations to send so that a client holding prev ends up with next.
+// *
+// * @details The operations are those of CreatePatch, in the same deterministic order, except that where
+// * the changes inside an object or array would take more bytes to send than the new value itself, they
+// * are replaced by a single "replace" of the whole container. Identical documents give no operations.
+// * Values CreatePatch can't diff are sent as a replace of the root.
+// *
+// * @param prev The document the client has.
+// * @param next The document it should have.
+// * @return The operations, to be applied in order.
+// */
+func StreamPatches(prev, next interface{}) []Operation {
+	var raw []interface{}
+	if err := streamDiff("", prev, next, &raw); err != nil {
+		raw = []interface{}{patchOp("replace", "", next)}
+	}
+	ops := make([]Operation, len(raw))
+	for i, r := range raw {
+		op := r.(map[string]interface{})
+		ops[i] = Operation{Op: op["op"].(string), Path: op["path"].(string), Value: op["value"]}
+	}
+	return ops
+}
+
+// /**
+// * @brief Applies operations from StreamPatches to a document.
+// *
+// * @param doc The document to patch; it isn't modified.
+// * @param ops The operations.
+// * @return The patched document or an error naming the failing operation.
+// */
+func ApplyOperations(doc interface{}, ops []Operation) (interface{}, error) {
+	patch := make([]interface{}, len(ops))
+	for i, o := range ops {
+		patch[i] = patchOp(o.Op, o.Path, o.Value)
+	}
+	return ApplyPatch(doc, patch)
+}
+
+// /**
+// * @brief Appends the operations turning a into b at path, collapsing them into one replace when cheaper.
+// *
+// * @details Objects are walked like diffObjects so each member can collapse on its own; arrays and
+// * scalars are diffed by diffValues.
+// */
+func streamDiff(path string, a, b interface{}, ops *[]interface{}) error {
+	var sub []interface{}
+	av, aObj := a.(map[string]interface{})
+	bv, bObj := b.(map[string]interface{})
+	if aObj && bObj {
+		var removed, kept, added []string
+		for k := range av {
+			if _, ok := bv[k]; ok {
+				kept = append(kept, k)
+			} else {
+				removed = append(removed, k)
+			}
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				added = append(added, k)
+			}
+		}
+		sort.Strings(removed)
+		sort.Strings(kept)
+		sort.Strings(added)
+		for _, k := range removed {
+			sub = append(sub, patchOp("remove", path+"/"+escapePointerToken(k), nil))
+		}
+		for _, k := range kept {
+			if err := streamDiff(path+"/"+escapePointerToken(k), av[k], bv[k], &sub); err != nil {
+				return err
+			}
+		}
+		for _, k := range added {
+			sub = append(sub, patchOp("add", path+"/"+escapePointerToken(k), bv[k]))
+		}
+	} else if err := diffValues(path, a, b, &sub); err != nil {
+		return err
+	}
+
+	if len(sub) > 1 {
+		replace := patchOp("replace", path, b)
+		if diff, err := Marshal(sub); err == nil && len(compactString(replace))+2 < len(diff) {
+			sub = []interface{}{replace}
+		}
+	}
+	*ops = append(*ops, sub...)
+	return nil
+}
@@ -0,0 +1,206 @@
+package jsonparser
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCreatePatchRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		wantOps int
+	}{
+		{"identical", `{"a": [1, 2]}`, `{"a": [1, 2]}`, 0},
+		{"scalar change", `{"a": 1}`, `{"a": 2}`, 1},
+		{"added and removed keys", `{"a": 1, "b": 2}`, `{"b": 2, "c": 3}`, 2},
+		{"insert into array", `[1, 2, 3, 4, 5]`, `[1, 2, 9, 3, 4, 5]`, 1},
+		{"remove from array", `[1, 2, 3, 4, 5]`, `[1, 3, 4, 5]`, 1},
+		{"nested change", `{"x": {"y": [true, {"z": null}]}}`, `{"x": {"y": [true, {"z": "s"}]}}`, 1},
+		{"type change", `{"a": [1]}`, `{"a": {"0": 1}}`, 1},
+		{"escaped keys", `{"a/b": 1, "c~d": 2}`, `{"a/b": 3}`, 2},
+		{"root replace", `1`, `"one"`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+			patch, err := CreatePatch(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(patch) != tt.wantOps {
+				t.Errorf("got %d operations %v, want %d", len(patch), patch, tt.wantOps)
+			}
+			got, err := ApplyPatch(a, patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(got, b) {
+				t.Errorf("applying %v gave %v, want %v", patch, got, b)
+			}
+			if !Equal(a, mustParse(t, tt.a)) {
+				t.Errorf("ApplyPatch modified its input: %v", a)
+			}
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr string
+	}{
+		{"move", `{"a": {"b": 1}, "c": []}`, `[{"op": "move", "from": "/a/b", "path": "/c/0"}]`, `{"a": {}, "c": [1]}`, ""},
+		{"copy is independent", `{"a": {"b": 1}}`, `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "replace", "path": "/c/b", "value": 2}]`, `{"a": {"b": 1}, "c": {"b": 2}}`, ""},
+		{"move onto itself", `{"a": 1}`, `[{"op": "move", "from": "/a", "path": "/a"}]`, `{"a": 1}`, ""},
+		{"move into own child", `{"a": {"b": 1}}`, `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`, "", "into its own child"},
+		{"move root into child", `{"a": 1}`, `[{"op": "move", "from": "", "path": "/b"}]`, "", "into its own child"},
+		{"move to sibling with common prefix", `{"a": 1}`, `[{"op": "move", "from": "/a", "path": "/ab"}]`, `{"ab": 1}`, ""},
+		{"failed test", `{"a": 1}`, `[{"op": "test", "path": "/a", "value": 2}]`, "", "test failed"},
+		{"unknown op", `{}`, `[{"op": "frob", "path": "/a"}]`, "", `unknown op "frob"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPatch(mustParse(t, tt.doc), mustParse(t, tt.patch).([]interface{}))
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && !Equal(got, mustParse(t, tt.want)) {
+				t.Errorf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPatchFailureLeavesDocumentUnchanged(t *testing.T) {
+	doc := mustParse(t, `{"a": 1, "list": [1, 2], "obj": {"k": "v"}}`)
+	patch := mustParse(t, `[
+		{"op": "replace", "path": "/a", "value": 2},
+		{"op": "add", "path": "/list/-", "value": 3},
+		{"op": "remove", "path": "/obj/k"},
+		{"op": "test", "path": "/a", "value": 1}
+	]`).([]interface{})
+	if _, err := ApplyPatch(doc, patch); err == nil {
+		t.Fatal("expected the final test operation to fail")
+	}
+	if want := mustParse(t, `{"a": 1, "list": [1, 2], "obj": {"k": "v"}}`); !Equal(doc, want) {
+		t.Errorf("failed patch changed the document to %v", doc)
+	}
+}
+
+// mustParse parses s or fails the test.
+func mustParse(t *testing.T, s string) interface{} {
+	t.Helper()
+	v, err := ParseJSON(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return v
+}
+
+func TestEqualNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{"float and float", 1.0, 1.0, true},
+		{"int and int", int64(7), int64(7), true},
+		{"int and equal float", int64(1), 1.0, true},
+		{"float and equal int", 1.0, int64(1), true},
+		{"int and fraction", int64(1), 1.5, false},
+		{"int beyond float precision", int64(9007199254740993), 9007199254740992.0, false},
+		{"float beyond int64", int64(math.MaxInt64), math.Pow(2, 63), false},
+		{"int and string", int64(1), "1", false},
+		{"mixed inside containers", map[string]interface{}{"a": []interface{}{int64(2)}}, map[string]interface{}{"a": []interface{}{2.0}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePatchPreservedIntegers(t *testing.T) {
+	opts := ParseOptions{PreserveIntegers: true}
+	a, _ := ParseJSONWithOptions(`{"id": 9007199254740993, "n": 1}`, opts)
+	b, _ := ParseJSONWithOptions(`{"id": 9007199254740995, "n": 1.0}`, opts)
+	patch, err := CreatePatch(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patch) != 1 {
+		t.Fatalf("got %v, want one replace of /id", patch)
+	}
+	got, err := ApplyPatch(a, patch)
+	if err != nil || !Equal(got, b) {
+		t.Errorf("ApplyPatch = %v, %v; want %v", got, err, b)
+	}
+}
+
+func TestStreamPatches(t *testing.T) {
+	const long = `"a value long enough that resending it costs more than the change"`
+	tests := []struct {
+		name string
+		prev string
+		next string
+		want string // the operations as a JSON Patch
+	}{
+		{"identical", `{"a": [1, 2]}`, `{"a": [1, 2]}`, `[]`},
+		{"one member", `{"a": 1, "b": 2}`, `{"a": 1, "b": 3}`, `[{"op": "replace", "path": "/b", "value": 3}]`},
+		{"separate changes in sorted order", `{"keep": ` + long + `, "n": 1, "m": 2}`, `{"keep": ` + long + `, "n": 7, "m": 8}`,
+			`[{"op": "replace", "path": "/m", "value": 8}, {"op": "replace", "path": "/n", "value": 7}]`},
+		{"removes before adds", `{"keep": ` + long + `, "old": 1}`, `{"keep": ` + long + `, "new": 2}`,
+			`[{"op": "remove", "path": "/old"}, {"op": "add", "path": "/new", "value": 2}]`},
+		{"small object sent whole", `{"stats": {"cpu": 1, "mem": 2, "disk": 3}}`, `{"stats": {"cpu": 4, "mem": 5, "disk": 6}}`,
+			`[{"op": "replace", "path": "/stats", "value": {"cpu": 4, "mem": 5, "disk": 6}}]`},
+		{"small root sent whole", `{"a/b": 1, "c~d": 2}`, `{"a/b": 3}`, `[{"op": "replace", "path": "", "value": {"a/b": 3}}]`},
+		{"deep change", `{"x": {"y": [true, ` + long + `]}}`, `{"x": {"y": [false, ` + long + `]}}`,
+			`[{"op": "replace", "path": "/x/y/0", "value": false}]`},
+		{"array insert", `[1, 2, 3, 4, 5]`, `[1, 2, 9, 3, 4, 5]`, `[{"op": "add", "path": "/2", "value": 9}]`},
+		{"array removal", `[` + long + `, 1, ` + long + `]`, `[` + long + `, ` + long + `]`, `[{"op": "remove", "path": "/1"}]`},
+		{"type change", `{"a": [1]}`, `{"a": {"0": 1}}`, `[{"op": "replace", "path": "/a", "value": {"0": 1}}]`},
+		{"root scalar", `1`, `"one"`, `[{"op": "replace", "path": "", "value": "one"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, next := mustParse(t, tt.prev), mustParse(t, tt.next)
+			ops := StreamPatches(prev, next)
+			patch := make([]interface{}, len(ops))
+			for i, o := range ops {
+				patch[i] = patchOp(o.Op, o.Path, o.Value)
+			}
+			if !Equal(patch, mustParse(t, tt.want)) {
+				t.Errorf("StreamPatches = %s, want %s", compactString(patch), tt.want)
+			}
+
+			got, err := ApplyOperations(prev, ops)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(got, next) {
+				t.Errorf("applying %s gave %v, want %v", compactString(patch), got, next)
+			}
+
+			// Every operation is needed: leaving any one out doesn't reach next.
+			for i := range ops {
+				rest := append(append([]Operation{}, ops[:i]...), ops[i+1:]...)
+				if got, err := ApplyOperations(prev, rest); err == nil && Equal(got, next) {
+					t.Errorf("operation %d (%s %s) is redundant", i, ops[i].Op, ops[i].Path)
+				}
+			}
+
+			// The order doesn't depend on map iteration.
+			for i := 0; i < 10; i++ {
+				again := StreamPatches(mustParse(t, tt.prev), mustParse(t, tt.next))
+				if !reflect.DeepEqual(again, ops) {
+					t.Fatalf("StreamPatches gave %v, then %v", ops, again)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package jsonparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// / pathSegment is one step of a dotted path: either an object key or an array index.
+type pathSegment struct {
+	Key     string ///< object key, when IsIndex is false
+	Index   int    ///< array index, when IsIndex is true
+	IsIndex bool
+}
+
+// /**
+// * @brief Parses a dotted/bracket path such as `store.book[0].price`.
+// *
+// * @details Keys are separated by '.', array indices are written as `[N]` and keys containing
+// * dots or brackets can be quoted as `["a.b"]`. The empty path refers to the root.
+// *
+// * @param path The path expression.
+// * @return The parsed segments or an error describing the malformed part.
+// */
+func parsePath(path string) ([]pathSegment, error) {
+	var segs []pathSegment
+	index := 0
+	for index < len(path) {
+		if path[index] == '[' {
+			end := strings.IndexByte(path[index:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated '[' at %d in path %q", index, path)
+			}
+			inner := path[index+1 : index+end]
+			if len(inner) >= 2 && inner[0] == '"' && inner[len(inner)-1] == '"' {
+				/// Quoted key: ["a.b"]
+				key, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid quoted key at %d in path %q", index, path)
+				}
+				segs = append(segs, pathSegment{Key: key})
+			} else {
+				/// Array index: [N]
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid array index %q at %d in path %q", inner, index, path)
+				}
+				segs = append(segs, pathSegment{Index: n, IsIndex: true})
+			}
+			index += end + 1
+		} else {
+			if path[index] == '.' {
+				if len(segs) == 0 {
+					return nil, fmt.Errorf("path %q must not start with '.'", path)
+				}
+				index++
+			}
+			start := index
+			for index < len(path) && path[index] != '.' && path[index] != '[' {
+				index++
+			}
+			if index == start {
+				return nil, fmt.Errorf("empty key at %d in path %q", start, path)
+			}
+			segs = append(segs, pathSegment{Key: path[start:index]})
+		}
+	}
+	return segs, nil
+}
+
+// /**
+// * @brief Follows parsed path segments from the root to the value they name.
+// *
+// * @param root The parsed JSON value.
+// * @param segs The segments returned by parsePath.
+// * @return The value at the path or an error naming the first segment that doesn't resolve.
+// */
+func resolvePath(root interface{}, segs []pathSegment) (interface{}, error) {
+	current := root
+	for i, seg := range segs {
+		switch v := untypedSlice(current).(type) {
+		case map[string]interface{}:
+			if seg.IsIndex {
+				return nil, fmt.Errorf("cannot index object with [%d] at %s", seg.Index, formatPath(segs[:i+1]))
+			}
+			val, ok := v[seg.Key]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found at %s", seg.Key, formatPath(segs[:i+1]))
+			}
+			current = val
+		case []interface{}:
+			if !seg.IsIndex {
+				return nil, fmt.Errorf("cannot look up key %q in array at %s", seg.Key, formatPath(segs[:i+1]))
+			}
+			if seg.Index >= len(v) {
+				return nil, fmt.Errorf("index %d out of range (length %d) at %s", seg.Index, len(v), formatPath(segs[:i+1]))
+			}
+			current = v[seg.Index]
+		default:
+			return nil, fmt.Errorf("cannot descend into scalar at %s", formatPath(segs[:i+1]))
+		}
+	}
+	return current, nil
+}
+
+// /**
+// * @brief Formats path segments back into dotted/bracket notation.
+// *
+// * @param segs The segments to format.
+// * @return The path string.
+// */
+func formatPath(segs []pathSegment) string {
+	var sb strings.Builder
+	for i, seg := range segs {
+		switch {
+		case seg.IsIndex:
+			sb.WriteString("[" + strconv.Itoa(seg.Index) + "]")
+		case strings.ContainsAny(seg.Key, ".[]") || seg.Key == "":
+			sb.WriteString("[" + strconv.Quote(seg.Key) + "]")
+		default:
+			if i > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(seg.Key)
+		}
+	}
+	return sb.String()
+}
+
+// /**
+// * @brief Returns the value at a dotted/bracket path such as `store.book[0].price`.
+// *
+// * @param root The parsed JSON value.
+// * @param path The path of the value to get; the empty path is the whole document.
+// * @return The value at the path or an error naming the first segment that doesn't resolve.
+// */
+func GetPath(root interface{}, path string) (interface{}, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return resolvePath(root, segs)
+}
+
+// /**
+// * @brief Converts a dotted/bracket path to the JSON Pointer naming the same value.
+// *
+// * @details `users[0].name` becomes `/users/0/name`. The conversion is purely textual, so the pointer
+// * is not checked against any document.
+// *
+// * @param path The dotted/bracket path.
+// * @return The JSON Pointer, or an error if the path is malformed.
+// */
+func PathPointer(path string) (string, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, seg := range segs {
+		if seg.IsIndex {
+			sb.WriteString("/" + strconv.Itoa(seg.Index))
+		} else {
+			sb.WriteString("/" + escapePointerToken(seg.Key))
+		}
+	}
+	return sb.String(), nil
+}
+
+// / SetOptions controls how SetWithOptions treats paths that don't exist yet.
+type SetOptions struct {
+	CreateMissing bool ///< create missing intermediate objects (or arrays, before an index step) instead of failing
+}
+
+// /**
+// * @brief Replaces the value at a dotted/bracket path such as `store.book[0].price`.
+// *
+// * @details Objects along the path are updated in place. Setting a missing key of an existing object
+// * adds it, and setting the index equal to an array's length appends to it. The empty path replaces the
+// * whole document.
+// *
+// * @param root The parsed JSON value.
+// * @param path The path of the value to set.
+// * @param newValue The value to store.
+// * @return The modified document, which must be used instead of root, or an error.
+// */
+func Set(root interface{}, path string, newValue interface{}) (interface{}, error) {
+	return SetWithOptions(root, path, newValue, SetOptions{})
+}
+
+// /**
+// * @brief Replaces the value at a dotted/bracket path, creating missing parents when asked to.
+// *
+// * @param root The parsed JSON value.
+// * @param path The path of the value to set.
+// * @param newValue The value to store.
+// * @param opts Whether missing intermediate containers are created.
+// * @return The modified document, which must be used instead of root, or an error.
+// */
+func SetWithOptions(root interface{}, path string, newValue interface{}, opts SetOptions) (interface{}, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return setPath(root, segs, 0, newValue, &opts)
+}
+
+// /**
+// * @brief Stores newValue below current at segs[i:], returning current's replacement.
+// *
+// * @details Arrays are returned anew when appended to, so every caller stores the result back.
+// *
+// * @param current The value segs[i] indexes into.
+// * @param segs The parsed path.
+// * @param i The index of the segment to apply.
+// * @param newValue The value to store.
+// * @param opts The set options.
+// * @return The updated current value or an error.
+// */
+func setPath(current interface{}, segs []pathSegment, i int, newValue interface{}, opts *SetOptions) (interface{}, error) {
+	if i == len(segs) {
+		return newValue, nil
+	}
+	seg := segs[i]
+	if current == nil && opts.CreateMissing {
+		if seg.IsIndex {
+			current = []interface{}{}
+		} else {
+			current = map[string]interface{}{}
+		}
+	}
+	/// A typed slice is boxed here and the boxed copy returned, since every caller stores the result.
+	switch v := untypedSlice(current).(type) {
+	case map[string]interface{}:
+		if seg.IsIndex {
+			return nil, fmt.Errorf("cannot index object with [%d] at %s", seg.Index, formatPath(segs[:i+1]))
+		}
+		child, ok := v[seg.Key]
+		if !ok && i < len(segs)-1 && !opts.CreateMissing {
+			return nil, fmt.Errorf("key %q not found at %s", seg.Key, formatPath(segs[:i+1]))
+		}
+		updated, err := setPath(child, segs, i+1, newValue, opts)
+		if err != nil {
+			return nil, err
+		}
+		v[seg.Key] = updated
+		return v, nil
+	case []interface{}:
+		if !seg.IsIndex {
+			return nil, fmt.Errorf("cannot look up key %q in array at %s", seg.Key, formatPath(segs[:i+1]))
+		}
+		if seg.Index > len(v) || (seg.Index == len(v) && i < len(segs)-1 && !opts.CreateMissing) {
+			return nil, fmt.Errorf("index %d out of range (length %d) at %s", seg.Index, len(v), formatPath(segs[:i+1]))
+		}
+		if seg.Index == len(v) {
+			/// Setting one past the end appends.
+			v = append(v, nil)
+		}
+		updated, err := setPath(v[seg.Index], segs, i+1, newValue, opts)
+		if err != nil {
+			return nil, err
+		}
+		v[seg.Index] = updated
+		return v, nil
+	}
+	return nil, fmt.Errorf("cannot descend into scalar at %s", formatPath(segs[:i+1]))
+}
@@ -0,0 +1,343 @@
+package jsonparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// /**
+// * @brief Splits an RFC 6901 JSON Pointer into its unescaped reference tokens.
+// *
+// * @details The empty pointer refers to the whole document. Otherwise the pointer must start with '/';
+// * "~1" decodes to '/' and "~0" to '~'.
+// *
+// * @param pointer The JSON Pointer, e.g. "/users/0/name".
+// * @return The reference tokens or an error for a malformed pointer.
+// */
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with '/'", pointer)
+	}
+	refs := strings.Split(pointer[1:], "/")
+	for i, ref := range refs {
+		if !strings.Contains(ref, "~") {
+			continue
+		}
+		for j := 0; j < len(ref); j++ {
+			if ref[j] == '~' && (j+1 >= len(ref) || (ref[j+1] != '0' && ref[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid JSON pointer %q: bad escape in %q", pointer, ref)
+			}
+		}
+		refs[i] = strings.ReplaceAll(strings.ReplaceAll(ref, "~1", "/"), "~0", "~")
+	}
+	return refs, nil
+}
+
+// /**
+// * @brief Converts a reference token into an array index.
+// *
+// * @details RFC 6901 indices are plain decimal numbers without leading zeros.
+// *
+// * @param ref The reference token.
+// * @param length The length of the array being indexed.
+// * @return The index or an error if it is malformed or out of range.
+// */
+func pointerIndex(ref string, length int) (int, error) {
+	if ref == "" || (len(ref) > 1 && ref[0] == '0') || strings.TrimLeft(ref, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", ref)
+	}
+	i, err := strconv.Atoi(ref)
+	if err != nil || i >= length {
+		return 0, fmt.Errorf("array index %s out of range (length %d)", ref, length)
+	}
+	return i, nil
+}
+
+// /**
+// * @brief Looks up the value a JSON Pointer refers to in a parsed tree.
+// *
+// * @param root The parsed JSON value.
+// * @param pointer The RFC 6901 JSON Pointer ("" for the root).
+// * @return The referenced value or an error if the pointer doesn't resolve.
+// */
+func Get(root interface{}, pointer string) (interface{}, error) {
+	refs, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := root
+	for _, ref := range refs {
+		switch v := untypedSlice(current).(type) {
+		case map[string]interface{}:
+			val, ok := v[ref]
+			if !ok {
+				return nil, fmt.Errorf("pointer %q: key %q not found", pointer, ref)
+			}
+			current = val
+		case []interface{}:
+			i, err := pointerIndex(ref, len(v))
+			if err != nil {
+				return nil, fmt.Errorf("pointer %q: %v", pointer, err)
+			}
+			current = v[i]
+		default:
+			return nil, fmt.Errorf("pointer %q: cannot descend into scalar at %q", pointer, ref)
+		}
+	}
+	return current, nil
+}
+
+// /**
+// * @brief Looks up the value a JSON Pointer refers to and converts it to the type T.
+// *
+// * @details A value that already has type T is returned as is. Anything else is converted the way
+// * encoding/json would decode it into T, so numbers become any integer or float type (integers only
+// * when the value is whole), objects become structs or typed maps, and arrays become typed slices.
+// *
+// * @param root The parsed JSON value.
+// * @param pointer The RFC 6901 JSON Pointer ("" for the root).
+// * @return The converted value, or an error if the pointer doesn't resolve or the value doesn't fit T.
+// */
+func As[T any](root interface{}, pointer string) (T, error) {
+	var result T
+	value, err := Get(root, pointer)
+	if err != nil {
+		return result, err
+	}
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	data, err := Marshal(value)
+	if err == nil {
+		err = json.Unmarshal(data, &result)
+	}
+	if err != nil {
+		return result, fmt.Errorf("pointer %q: cannot convert %s to %T: %v", pointer, valueKind(value), result, err)
+	}
+	return result, nil
+}
+
+// /**
+// * @brief Parses only the value a JSON Pointer refers to, straight from the source text.
+// *
+// * @details The input is scanned token by token without building a tree: object members and array
+// * elements that aren't on the pointer's path are skipped over, and only the targeted subtree is parsed.
+// * Skipped values are checked token by token but not for full structural validity.
+// *
+// * @param s The JSON document.
+// * @param pointer The RFC 6901 JSON Pointer ("" for the root).
+// * @return The parsed subtree or an error.
+// */
+func GetRaw(s string, pointer string) (interface{}, error) {
+	refs, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	start, err := locateValue(s, refs, &ParseOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("pointer %q: %v", pointer, err)
+	}
+	end, err := skipValue(s, start, &ParseOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSON(s[start:end])
+}
+
+// /**
+// * @brief Finds the offset where the value named by the reference tokens starts.
+// *
+// * @param s The JSON document.
+// * @param refs The unescaped pointer reference tokens.
+// * @param opts The parse options; AllowComments and AllowTrailingCommas are honored while scanning.
+// * @return The offset of the value's first byte or an error.
+// */
+func locateValue(s string, refs []string, opts *ParseOptions) (int, error) {
+	token, next, err := nextToken(s, 0, opts)
+	if err != nil {
+		return 0, err
+	}
+	index := token.Offset
+	for _, ref := range refs {
+		switch token.Type {
+		case TokenObjectStart:
+			index, err = locateMember(s, next, ref, opts)
+		case TokenArrayStart:
+			index, err = locateElement(s, next, ref, opts)
+		default:
+			return 0, fmt.Errorf("cannot descend into scalar at %q", ref)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if token, next, err = nextToken(s, index, opts); err != nil {
+			return 0, err
+		}
+	}
+	return index, nil
+}
+
+// /**
+// * @brief Scans an object's members for a key, skipping the values of the others.
+// *
+// * @details The whole object is scanned, so when the key is repeated the last member wins, the same as
+// * in the tree ParseJSON builds.
+// *
+// * @param s The JSON document.
+// * @param index The offset just past the '{'.
+// * @param key The member to find.
+// * @param opts The parse options.
+// * @return The offset of the member's value or an error.
+// */
+func locateMember(s string, index int, key string, opts *ParseOptions) (int, error) {
+	first, found := true, -1
+	for {
+		token, next, err := nextToken(s, index, opts)
+		if err != nil {
+			return 0, err
+		}
+		if !first && token.Type == TokenComma {
+			if token, next, err = nextToken(s, next, opts); err != nil {
+				return 0, err
+			}
+			if token.Type == TokenObjectEnd && !opts.AllowTrailingCommas {
+				return 0, fmt.Errorf("trailing comma before '}' at %d", token.Offset)
+			}
+		} else if !first && token.Type != TokenObjectEnd {
+			return 0, fmt.Errorf("expected ',' or '}' at %d", token.Offset)
+		}
+		if token.Type == TokenObjectEnd {
+			if found >= 0 {
+				return found, nil
+			}
+			return 0, fmt.Errorf("key %q not found", key)
+		}
+		if token.Type != TokenString {
+			return 0, fmt.Errorf("expected string key at %d", token.Offset)
+		}
+		colon, next, err := nextToken(s, next, opts)
+		if err != nil {
+			return 0, err
+		}
+		if colon.Type != TokenColon {
+			return 0, fmt.Errorf("expected ':' at %d", colon.Offset)
+		}
+		value, _, err := nextToken(s, next, opts)
+		if err != nil {
+			return 0, err
+		}
+		name, ok := token.Value.(string)
+		if !ok {
+			return 0, fmt.Errorf("string key at %d has no string value", token.Offset)
+		}
+		if name == key {
+			found = value.Offset
+		}
+		if index, err = skipValue(s, value.Offset, opts); err != nil {
+			return 0, err
+		}
+		first = false
+	}
+}
+
+// /**
+// * @brief Scans an array's elements for an index, skipping the elements before it.
+// *
+// * @param s The JSON document.
+// * @param index The offset just past the '['.
+// * @param ref The reference token naming the element.
+// * @param opts The parse options.
+// * @return The offset of the element or an error.
+// */
+func locateElement(s string, index int, ref string, opts *ParseOptions) (int, error) {
+	want, err := pointerIndex(ref, int(^uint(0)>>1))
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; ; i++ {
+		token, next, err := nextToken(s, index, opts)
+		if err != nil {
+			return 0, err
+		}
+		if i > 0 && token.Type == TokenComma {
+			if token, next, err = nextToken(s, next, opts); err != nil {
+				return 0, err
+			}
+			if token.Type == TokenArrayEnd && !opts.AllowTrailingCommas {
+				return 0, fmt.Errorf("trailing comma before ']' at %d", token.Offset)
+			}
+		} else if i > 0 && token.Type != TokenArrayEnd {
+			return 0, fmt.Errorf("expected ',' or ']' at %d", token.Offset)
+		}
+		if token.Type == TokenArrayEnd {
+			return 0, fmt.Errorf("array index %d out of range (length %d)", want, i)
+		}
+		if i == want {
+			return token.Offset, nil
+		}
+		if index, err = skipValue(s, token.Offset, opts); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// /**
+// * @brief Scans the next token after any whitespace and comments, failing at end of input.
+// *
+// * @param s The JSON document.
+// * @param index The offset to scan from.
+// * @param opts The parse options selecting grammar extensions.
+// * @return The token, the offset just past it, and any error.
+// */
+func nextToken(s string, index int, opts *ParseOptions) (Token, int, error) {
+	for {
+		index = skipWhitespace(s, index)
+		if index >= len(s) {
+			return Token{}, index, fmt.Errorf("unexpected end of input")
+		}
+		token, next, err := scanToken(newLineCounter(s), index, opts)
+		if err != nil || token.Type != TokenComment {
+			return token, next, err
+		}
+		index = next
+	}
+}
+
+// /**
+// * @brief Skips over one complete value without building it.
+// *
+// * @details Scalars are scanned as a single token. Objects and arrays are skipped by tracking bracket
+// * depth until the matching close.
+// *
+// * @param s The JSON document.
+// * @param index The offset of the value's first byte.
+// * @param opts The parse options selecting grammar extensions.
+// * @return The offset just past the value or an error.
+// */
+func skipValue(s string, index int, opts *ParseOptions) (int, error) {
+	depth := 0
+	for {
+		token, next, err := nextToken(s, index, opts)
+		if err != nil {
+			return 0, err
+		}
+		index = next
+		switch token.Type {
+		case TokenObjectStart, TokenArrayStart:
+			depth++
+		case TokenObjectEnd, TokenArrayEnd:
+			depth--
+			if depth < 0 {
+				return 0, fmt.Errorf("unexpected closing bracket at %d", token.Offset)
+			}
+		}
+		if depth == 0 {
+			return index, nil
+		}
+	}
+}
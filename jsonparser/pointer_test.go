@@ -0,0 +1,138 @@
+package jsonparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetRaw(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		pointer string
+		want    interface{}
+		wantErr string
+	}{
+		{"root", `[1, 2]`, "", []interface{}{1.0, 2.0}, ""},
+		{"nested member", `{"a": {"b": [true, null]}}`, "/a/b/0", true, ""},
+		{"skips other members", `{"x": [1, {"y": 2}], "a": "hit"}`, "/a", "hit", ""},
+		{"duplicate key, last wins", `{"a": 1, "b": 0, "a": 2}`, "/a", 2.0, ""},
+		{"duplicate nested object, last wins", `{"a": {"b": 1}, "a": {"b": 2}}`, "/a/b", 2.0, ""},
+		{"missing key", `{"a": 1}`, "/b", nil, `key "b" not found`},
+		{"index out of range", `[1]`, "/3", nil, "out of range"},
+		{"scalar", `{"a": 1}`, "/a/b", nil, "cannot descend into scalar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetRaw(tt.input, tt.pointer)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRaw = %#v, want %#v", got, tt.want)
+			}
+			root, err := ParseJSON(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if full, err := Get(root, tt.pointer); err != nil || !reflect.DeepEqual(full, got) {
+				t.Errorf("ParseJSON+Get = %#v, %v; GetRaw = %#v", full, err, got)
+			}
+		})
+	}
+}
+
+// wideDocument builds an object with n members, each holding a small object, for pointer benchmarks.
+func wideDocument(n int) string {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `"key%d": {"id": %d, "name": "item %d", "tags": ["a", "b"]}`, i, i, i)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func BenchmarkGetRaw(b *testing.B) {
+	doc := wideDocument(5000)
+	b.SetBytes(int64(len(doc)))
+	for i := 0; i < b.N; i++ {
+		if _, err := GetRaw(doc, "/key4999/name"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseJSONGet(b *testing.B) {
+	doc := wideDocument(5000)
+	b.SetBytes(int64(len(doc)))
+	for i := 0; i < b.N; i++ {
+		root, err := ParseJSON(doc)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Get(root, "/key4999/name"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAs(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+		Zip  int    `json:"zip"`
+	}
+	root := mustParse(t, `{"name": "ann", "age": 30, "height": 1.75, "admin": true, "nick": null,
+		"tags": ["a", "b"], "scores": [1, 2.5], "address": {"city": "Oslo", "zip": 150}, "big": 9007199254740993}`)
+	preserved, err := ParseJSONWithOptions(`{"big": 9007199254740993}`, ParseOptions{PreserveIntegers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		as      func() (interface{}, error)
+		want    interface{}
+		wantErr string
+	}{
+		{"int", func() (interface{}, error) { return As[int](root, "/age") }, 30, ""},
+		{"uint8", func() (interface{}, error) { return As[uint8](root, "/age") }, uint8(30), ""},
+		{"float64 as is", func() (interface{}, error) { return As[float64](root, "/height") }, 1.75, ""},
+		{"float32", func() (interface{}, error) { return As[float32](root, "/height") }, float32(1.75), ""},
+		{"string", func() (interface{}, error) { return As[string](root, "/name") }, "ann", ""},
+		{"bool", func() (interface{}, error) { return As[bool](root, "/admin") }, true, ""},
+		{"null into a pointer", func() (interface{}, error) { return As[*string](root, "/nick") }, (*string)(nil), ""},
+		{"typed slice", func() (interface{}, error) { return As[[]string](root, "/tags") }, []string{"a", "b"}, ""},
+		{"float slice", func() (interface{}, error) { return As[[]float64](root, "/scores") }, []float64{1, 2.5}, ""},
+		{"struct", func() (interface{}, error) { return As[address](root, "/address") }, address{"Oslo", 150}, ""},
+		{"typed map", func() (interface{}, error) { return As[map[string]string](root, "/address") }, nil,
+			`pointer "/address": cannot convert object to map[string]string`},
+		{"object as is", func() (interface{}, error) { return As[map[string]interface{}](root, "/address") },
+			map[string]interface{}{"city": "Oslo", "zip": float64(150)}, ""},
+		{"interface", func() (interface{}, error) { return As[interface{}](root, "/tags/1") }, "b", ""},
+		{"preserved integer stays exact", func() (interface{}, error) { return As[int64](preserved, "/big") }, int64(9007199254740993), ""},
+		{"string is not an int", func() (interface{}, error) { return As[int](root, "/name") }, 0,
+			`pointer "/name": cannot convert string to int`},
+		{"fraction is not an int", func() (interface{}, error) { return As[int](root, "/height") }, 0,
+			`pointer "/height": cannot convert number to int`},
+		{"number is not a string", func() (interface{}, error) { return As[string](root, "/age") }, "",
+			`pointer "/age": cannot convert number to string`},
+		{"fits in uint8", func() (interface{}, error) { return As[uint8](root, "/address/zip") }, uint8(150), ""},
+		{"overflow", func() (interface{}, error) { return As[int8](root, "/address/zip") }, int8(0), "cannot convert number to int8"},
+		{"missing member", func() (interface{}, error) { return As[int](root, "/missing") }, 0, "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.as()
+			checkErr(t, err, tt.wantErr)
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
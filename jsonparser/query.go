@@ -0,0 +1,231 @@
+package jsonparser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// / queryStep is one step of a query path: a member lookup, an index, or an iteration.
+type queryStep struct {
+	Key   string
+	Index int
+	Kind  byte ///< 'k' member lookup, 'i' array index, 'e' iterate every element or member value
+}
+
+// /**
+// * @brief Parses a jq-style path expression such as .users[].name or .items[0]["a.b"].
+// *
+// * @details Supported steps are .name, ."quoted name", ["quoted name"], [N] (negative N counts from the
+// * end) and [] to iterate. A lone "." is the identity.
+// *
+// * @param expr The query expression.
+// * @return The steps or an error describing the first unsupported construct.
+// */
+func parseQuery(expr string) ([]queryStep, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, ".") && !strings.HasPrefix(expr, "[") {
+		return nil, fmt.Errorf("query must start with '.': %q", expr)
+	}
+	var steps []queryStep
+	i := 0
+	for i < len(expr) {
+		switch expr[i] {
+		case '.':
+			i++
+			if i >= len(expr) || expr[i] == '[' {
+				continue
+			}
+			if expr[i] == '"' {
+				key, n, err := parseString(expr, i)
+				if err != nil {
+					return nil, fmt.Errorf("bad quoted key at %d: %v", i, err)
+				}
+				steps = append(steps, queryStep{Key: key, Kind: 'k'})
+				i = n
+				continue
+			}
+			start := i
+			for i < len(expr) && (expr[i] == '_' || expr[i] == '-' ||
+				'a' <= expr[i] && expr[i] <= 'z' || 'A' <= expr[i] && expr[i] <= 'Z' || '0' <= expr[i] && expr[i] <= '9') {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("expected a key at %d", start)
+			}
+			steps = append(steps, queryStep{Key: expr[start:i], Kind: 'k'})
+		case '[':
+			j := skipWhitespace(expr, i+1)
+			if j < len(expr) && expr[j] == '"' {
+				/// Quoted keys may contain ']', so the string is scanned before looking for the bracket.
+				key, n, err := parseString(expr, j)
+				if err != nil {
+					return nil, fmt.Errorf("bad quoted key at %d: %v", j, err)
+				}
+				n = skipWhitespace(expr, n)
+				if n >= len(expr) || expr[n] != ']' {
+					return nil, fmt.Errorf("unclosed '[' at %d", i)
+				}
+				steps = append(steps, queryStep{Key: key, Kind: 'k'})
+				i = n + 1
+				continue
+			}
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '[' at %d", i)
+			}
+			inner := strings.TrimSpace(expr[i+1 : i+end])
+			if inner == "" {
+				steps = append(steps, queryStep{Kind: 'e'})
+			} else {
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("bad index %q at %d", inner, i)
+				}
+				steps = append(steps, queryStep{Index: idx, Kind: 'i'})
+			}
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", expr[i], i)
+		}
+	}
+	return steps, nil
+}
+
+// / queryHit is one value produced while evaluating a query, with where it was found.
+type queryHit struct {
+	value   interface{}
+	pointer string ///< JSON Pointer of value in the queried document
+	missing bool   ///< value is the null produced for a missing key or index, found nowhere
+}
+
+// /**
+// * @brief Evaluates a jq-style path expression against a parsed document.
+// *
+// * @details A small subset of jq for pulling values out of documents: .key, ."key", ["key"], [N] and
+// * the [] iterator, which fans out over array elements (or object values, in key order). Looking up a
+// * missing key or an out-of-range index yields null, as in jq; indexing the wrong type is an error.
+// *
+// * @param root The JSON value to query.
+// * @param expr The query expression, e.g. ".users[].name".
+// * @return Every value the expression produces, in order, or an error.
+// */
+func Query(root interface{}, expr string) ([]interface{}, error) {
+	hits, err := evalQuery(root, expr)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, len(hits))
+	for i, hit := range hits {
+		results[i] = hit.value
+	}
+	return results, nil
+}
+
+// /**
+// * @brief Evaluates a query like Query, returning where the results are instead of the results.
+// *
+// * @details The nulls Query produces for missing keys and out-of-range indices exist nowhere in the
+// * document, so they have no pointer and are left out.
+// *
+// * @param root The JSON value to query.
+// * @param expr The query expression, e.g. ".users[].name".
+// * @return The JSON Pointers of the results, in order, or an error.
+// */
+func QueryPaths(root interface{}, expr string) ([]string, error) {
+	hits, err := evalQuery(root, expr)
+	if err != nil {
+		return nil, err
+	}
+	var pointers []string
+	for _, hit := range hits {
+		if !hit.missing {
+			pointers = append(pointers, hit.pointer)
+		}
+	}
+	return pointers, nil
+}
+
+// /**
+// * @brief Parses and evaluates a query, step by step.
+// *
+// * @param root The JSON value to query.
+// * @param expr The query expression.
+// * @return Every result with its location, in order, or an error.
+// */
+func evalQuery(root interface{}, expr string) ([]queryHit, error) {
+	steps, err := parseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	results := []queryHit{{value: root}}
+	for _, step := range steps {
+		var next []queryHit
+		for _, hit := range results {
+			out, err := applyQueryStep(hit, step)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		results = next
+	}
+	return results, nil
+}
+
+// /**
+// * @brief Applies a single query step to one value.
+// *
+// * @param hit The input value and its location.
+// * @param step The step to apply.
+// * @return The values produced or an error.
+// */
+func applyQueryStep(hit queryHit, step queryStep) ([]queryHit, error) {
+	value := hit.value
+	if value == nil && step.Kind != 'e' {
+		return []queryHit{{missing: true}}, nil
+	}
+	switch step.Kind {
+	case 'k':
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot index %s with %q", valueKind(value), step.Key)
+		}
+		child, ok := obj[step.Key]
+		return []queryHit{{value: child, pointer: hit.pointer + "/" + escapePointerToken(step.Key), missing: !ok}}, nil
+	case 'i':
+		arr, ok := untypedSlice(value).([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot index %s with %d", valueKind(value), step.Index)
+		}
+		idx := step.Index
+		if idx < 0 {
+			idx += len(arr)
+		}
+		if idx < 0 || idx >= len(arr) {
+			return []queryHit{{missing: true}}, nil
+		}
+		return []queryHit{{value: arr[idx], pointer: hit.pointer + "/" + strconv.Itoa(idx)}}, nil
+	}
+	switch v := untypedSlice(value).(type) {
+	case []interface{}:
+		out := make([]queryHit, len(v))
+		for i, elem := range v {
+			out[i] = queryHit{value: elem, pointer: hit.pointer + "/" + strconv.Itoa(i)}
+		}
+		return out, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]queryHit, len(keys))
+		for i, k := range keys {
+			out[i] = queryHit{value: v[k], pointer: hit.pointer + "/" + escapePointerToken(k)}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", valueKind(value))
+}
@@ -0,0 +1,58 @@
+package jsonparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryPaths(t *testing.T) {
+	const doc = `{"users": [{"name": "ann", "tags": ["x", "y"]}, {"name": "bob"}], "a/b": {"~k": 1}, "z": null}`
+	tests := []struct {
+		name    string
+		input   string
+		opts    ParseOptions
+		expr    string
+		want    []string
+		wantErr string
+	}{
+		{"identity", doc, ParseOptions{}, ".", []string{""}, ""},
+		{"member", doc, ParseOptions{}, ".users", []string{"/users"}, ""},
+		{"every element", doc, ParseOptions{}, ".users[].name", []string{"/users/0/name", "/users/1/name"}, ""},
+		{"negative index", doc, ParseOptions{}, ".users[-1]", []string{"/users/1"}, ""},
+		{"escaped keys", doc, ParseOptions{}, `.["a/b"]["~k"]`, []string{"/a~1b/~0k"}, ""},
+		{"object values in key order", doc, ParseOptions{}, ".[]", []string{"/a~1b", "/users", "/z"}, ""},
+		{"nested iteration", doc, ParseOptions{}, ".users[0].tags[]", []string{"/users/0/tags/0", "/users/0/tags/1"}, ""},
+		{"null that is in the document", doc, ParseOptions{}, ".z", []string{"/z"}, ""},
+		{"missing keys are left out", doc, ParseOptions{}, ".users[].tags", []string{"/users/0/tags"}, ""},
+		{"out of range index is left out", doc, ParseOptions{}, ".users[5].name", nil, ""},
+		{"typed slices", `{"ids": [1, 2]}`, ParseOptions{TypedSlices: true}, ".ids[]", []string{"/ids/0", "/ids/1"}, ""},
+		{"bad query", doc, ParseOptions{}, "users", nil, "must start with '.'"},
+		{"index into an object", doc, ParseOptions{}, ".users[0][1]", nil, "cannot index object with 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ParseJSONWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := QueryPaths(root, tt.expr)
+			checkErr(t, err, tt.wantErr)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryPaths(%s) = %q, want %q", tt.expr, got, tt.want)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			// Every pointer resolves, to the value Query returns when no result was left out.
+			values, _ := Query(root, tt.expr)
+			for i, pointer := range got {
+				v, err := Get(root, pointer)
+				if err != nil {
+					t.Errorf("Get(%q): %v", pointer, err)
+				} else if len(got) == len(values) && !Equal(v, values[i]) {
+					t.Errorf("Get(%q) = %v, Query gave %v", pointer, v, values[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package jsonparser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// /**
+// * @brief Collects every distinct object key that appears anywhere in the tree.
+// *
+// * @details Useful as a quick schema sketch of an unfamiliar document. Keys from nested
+// * and repeated objects are reported once each.
+// *
+// * @param root The JSON value to inspect.
+// * @return The unique keys in sorted order.
+// */
+func UniqueKeys(root interface{}) []string {
+	seen := make(map[string]bool)
+	Walk(root, func(pointer string, value interface{}) error {
+		if obj, ok := value.(map[string]interface{}); ok {
+			for k := range obj {
+				seen[k] = true
+			}
+		}
+		return nil
+	})
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// /**
+// * @brief Lists the JSON Pointer of every value in the document.
+// *
+// * @details Handy for contract tests that assert a response has exactly an expected set of fields.
+// * Scalars, including a scalar root, are always listed. Objects and arrays, including empty ones and the
+// * root itself (""), are listed only when includeContainers is set.
+// *
+// * @param root The JSON value to inspect.
+// * @param includeContainers Whether to list object and array paths as well as leaf paths.
+// * @return The paths in sorted order.
+// */
+func Paths(root interface{}, includeContainers bool) []string {
+	var paths []string
+	Walk(root, func(pointer string, value interface{}) error {
+		if kind := valueKind(value); (kind == "object" || kind == "array") && !includeContainers {
+			return nil
+		}
+		paths = append(paths, pointer)
+		return nil
+	})
+	sort.Strings(paths)
+	return paths
+}
+
+// /**
+// * @brief Reports the JSON type of a document's root from its first significant byte.
+// *
+// * @details Only a UTF-8 byte-order mark and whitespace are skipped; nothing after the first significant
+// * byte is read, so the document is not validated. This makes it a cheap way to dispatch on the shape of
+// * input, e.g. to tell a JSON array from newline-delimited objects.
+// *
+// * @param s The JSON document.
+// * @return "object", "array", "string", "number", "boolean" or "null", or an error for empty or
+// * unrecognizable input.
+// */
+func RootKind(s string) (string, error) {
+	s = strings.TrimPrefix(s, "\uFEFF")
+	index := skipWhitespace(s, 0)
+	if index >= len(s) {
+		return "", fmt.Errorf("empty input")
+	}
+	switch c := s[index]; {
+	case c == '{':
+		return "object", nil
+	case c == '[':
+		return "array", nil
+	case c == '"':
+		return "string", nil
+	case c == '-' || '0' <= c && c <= '9':
+		return "number", nil
+	case c == 't' || c == 'f':
+		return "boolean", nil
+	case c == 'n':
+		return "null", nil
+	default:
+		return "", fmt.Errorf("unexpected character at %d: %c", index, c)
+	}
+}
@@ -0,0 +1,218 @@
+package jsonparser
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestUniqueKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"scalar root", `42`, []string{}},
+		{"empty object", `{}`, []string{}},
+		{"flat object, sorted", `{"b": 1, "a": 2, "c": 3}`, []string{"a", "b", "c"}},
+		{"nested objects", `{"user": {"name": "x", "address": {"city": "y"}}}`, []string{"address", "city", "name", "user"}},
+		{"repeated objects in an array", `[{"id": 1, "tags": []}, {"id": 2, "extra": true}, {"id": 3}]`, []string{"extra", "id", "tags"}},
+		{"same key at different depths", `{"id": 1, "child": {"id": 2, "child": {"id": 3}}}`, []string{"child", "id"}},
+		{"keys inside nested arrays", `[[{"a": 1}], [[{"b": 2}]]]`, []string{"a", "b"}},
+		{"empty and escaped keys", `{"": 1, "a/b": {"~x": 2}}`, []string{"", "a/b", "~x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UniqueKeys(mustParse(t, tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UniqueKeys = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	stop := errors.New("stop")
+	tests := []struct {
+		name     string
+		input    string
+		stopAt   string // pointer whose visit returns stop ("-" never stops)
+		want     []string
+		wantStop bool
+	}{
+		{"scalar root", `1`, "-", []string{""}, false},
+		{"containers before children, keys sorted", `{"b": [1, 2], "a": {"c": null}}`, "-",
+			[]string{"", "/a", "/a/c", "/b", "/b/0", "/b/1"}, false},
+		{"pointer tokens are escaped", `{"a/b": {"~": 1}}`, "-", []string{"", "/a~1b", "/a~1b/~0"}, false},
+		{"error stops the walk", `[1, [2, 3], 4]`, "/1/0", []string{"", "/0", "/1", "/1/0"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := Walk(mustParse(t, tt.input), func(pointer string, value interface{}) error {
+				got = append(got, pointer)
+				if pointer == tt.stopAt {
+					return stop
+				}
+				return nil
+			})
+			if (err == stop) != tt.wantStop {
+				t.Errorf("Walk returned %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("visited %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaths(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		wantLeaves     []string
+		wantContainers []string
+	}{
+		{"scalar root", `1`, []string{""}, []string{""}},
+		{"empty object", `{}`, nil, []string{""}},
+		{"members sorted whatever the source order", `{"b": 1, "a": {"d": null, "c": true}}`,
+			[]string{"/a/c", "/a/d", "/b"},
+			[]string{"", "/a", "/a/c", "/a/d", "/b"}},
+		{"empty containers are not leaves", `{"a": [], "b": {}, "c": 1}`,
+			[]string{"/c"},
+			[]string{"", "/a", "/b", "/c"}},
+		{"array indices sort as strings", `[0,1,2,3,4,5,6,7,8,9,10]`,
+			[]string{"/0", "/1", "/10", "/2", "/3", "/4", "/5", "/6", "/7", "/8", "/9"},
+			[]string{"", "/0", "/1", "/10", "/2", "/3", "/4", "/5", "/6", "/7", "/8", "/9"}},
+		{"escaped keys", `{"a/b": 1, "a": {"~": 2}}`,
+			[]string{"/a/~0", "/a~1b"},
+			[]string{"", "/a", "/a/~0", "/a~1b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, tt.input)
+			for _, c := range []struct {
+				includeContainers bool
+				want              []string
+			}{{false, tt.wantLeaves}, {true, tt.wantContainers}} {
+				got := Paths(v, c.includeContainers)
+				if !sort.StringsAreSorted(got) {
+					t.Errorf("Paths(%v) = %q is not sorted", c.includeContainers, got)
+				}
+				if !reflect.DeepEqual(got, c.want) {
+					t.Errorf("Paths(%v) = %q, want %q", c.includeContainers, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPathsTypedSlices(t *testing.T) {
+	v, err := ParseJSONWithOptions(`{"a": [1, 2], "b": ["x"]}`, ParseOptions{TypedSlices: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/a/0", "/a/1", "/b/0"}; !reflect.DeepEqual(Paths(v, false), want) {
+		t.Errorf("Paths(false) = %q, want %q", Paths(v, false), want)
+	}
+	if want := []string{"", "/a", "/a/0", "/a/1", "/b", "/b/0"}; !reflect.DeepEqual(Paths(v, true), want) {
+		t.Errorf("Paths(true) = %q, want %q", Paths(v, true), want)
+	}
+}
+
+func TestExtractStrings(t *testing.T) {
+	const doc = `{"title": "Intro", "tags": ["go", "json"], "meta": {"a/b": "x", "n": 1, "ok": true}, "rows": [["r1"], []]}`
+	tests := []struct {
+		name  string
+		input string
+		opts  ParseOptions
+		keys  bool
+		want  []ExtractedString
+	}{
+		{"no strings", `[1, null, {"a": false}]`, ParseOptions{}, false, nil},
+		{"string root", `"hello"`, ParseOptions{}, false, []ExtractedString{{Pointer: "", Text: "hello"}}},
+		{"nested values in Walk order", doc, ParseOptions{}, false, []ExtractedString{
+			{Pointer: "/meta/a~1b", Text: "x"},
+			{Pointer: "/rows/0/0", Text: "r1"},
+			{Pointer: "/tags/0", Text: "go"},
+			{Pointer: "/tags/1", Text: "json"},
+			{Pointer: "/title", Text: "Intro"},
+		}},
+		{"keys before their values", doc, ParseOptions{}, true, []ExtractedString{
+			{Pointer: "/meta", Text: "meta", IsKey: true},
+			{Pointer: "/meta/a~1b", Text: "a/b", IsKey: true},
+			{Pointer: "/meta/a~1b", Text: "x"},
+			{Pointer: "/meta/n", Text: "n", IsKey: true},
+			{Pointer: "/meta/ok", Text: "ok", IsKey: true},
+			{Pointer: "/rows", Text: "rows", IsKey: true},
+			{Pointer: "/rows/0/0", Text: "r1"},
+			{Pointer: "/tags", Text: "tags", IsKey: true},
+			{Pointer: "/tags/0", Text: "go"},
+			{Pointer: "/tags/1", Text: "json"},
+			{Pointer: "/title", Text: "title", IsKey: true},
+			{Pointer: "/title", Text: "Intro"},
+		}},
+		{"typed string slices", `{"tags": ["go", "json"]}`, ParseOptions{TypedSlices: true}, false, []ExtractedString{
+			{Pointer: "/tags/0", Text: "go"},
+			{Pointer: "/tags/1", Text: "json"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ParseJSONWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ExtractStringsWithOptions(root, ExtractOptions{IncludeKeys: tt.keys})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractStringsWithOptions = %+v, want %+v", got, tt.want)
+			}
+			if tt.keys {
+				return
+			}
+			var texts []string
+			for _, s := range tt.want {
+				texts = append(texts, s.Text)
+			}
+			if got := ExtractStrings(root); !reflect.DeepEqual(got, texts) {
+				t.Errorf("ExtractStrings = %q, want %q", got, texts)
+			}
+		})
+	}
+}
+
+func TestRootKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"object", `{"a": 1}`, "object", ""},
+		{"array", `[1, 2]`, "array", ""},
+		{"string", `"s"`, "string", ""},
+		{"number", `42`, "number", ""},
+		{"negative number", `-0.5`, "number", ""},
+		{"true", `true`, "boolean", ""},
+		{"false", `false`, "boolean", ""},
+		{"null", `null`, "null", ""},
+		{"leading whitespace", " \t\r\n [", "array", ""},
+		{"byte-order mark", "\ufeff {", "object", ""},
+		{"byte-order mark and whitespace", "\ufeff\n  null", "null", ""},
+		{"rest is not validated", `{not json`, "object", ""},
+		{"newline-delimited objects", "{}\n{}\n", "object", ""},
+		{"empty", "", "", "empty input"},
+		{"only whitespace", " \n\t", "", "empty input"},
+		{"only a byte-order mark", "\ufeff", "", "empty input"},
+		{"unexpected character", `  <html>`, "", "unexpected character at 2: <"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RootKind(tt.input)
+			checkErr(t, err, tt.wantErr)
+			if got != tt.want {
+				t.Errorf("RootKind = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
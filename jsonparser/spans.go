@@ -0,0 +1,34 @@
+package jsonparser
+
+// / Span is the part of the source text a parsed value was read from.
+type Span struct {
+	StartOffset int ///< byte offset of the value's first character
+	EndOffset   int ///< byte offset just past the value's last character, e.g. its closing bracket
+}
+
+// /**
+// * @brief Parses a JSON string, also recording where in the source each value starts and ends.
+// *
+// * @details Every value gets a Span, keyed by its JSON Pointer ("" for the root): objects and arrays
+// * from their opening to their closing bracket, strings including their quotes. Editors can use the
+// * spans to map values, and errors found in them, back to the text.
+// *
+// * @param jsonStr The JSON string to parse.
+// * @param opts The parse options selecting grammar extensions.
+// * @return The parsed value and the span of every value, or an error.
+// */
+func ParseWithSpans(jsonStr string, opts ParseOptions) (interface{}, map[string]Span, error) {
+	tokens, err := tokenizeWithOptions(jsonStr, &opts)
+	if err != nil {
+		if trailing := trailingText(jsonStr, &opts); trailing != nil {
+			return nil, nil, trailing
+		}
+		return nil, nil, err
+	}
+	ts := &TokenStream{tokens: tokens, opts: &opts, src: jsonStr, spans: make(map[string]Span)}
+	value, err := parseStream(ts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, ts.spans, nil
+}
@@ -0,0 +1,405 @@
+package jsonparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// / Tokenizer reads JSON tokens one at a time from a stream, such as a file or network connection.
+// / Input is consumed only as far as the token being returned, so values can be processed while the
+// / rest of the document is still arriving.
+type Tokenizer struct {
+	r      *bufio.Reader
+	opts   *ParseOptions
+	offset int   ///< byte offset of the next unread byte
+	line   int   ///< 1-based line of the next unread byte
+	col    int   ///< 1-based column, in characters, of the next unread byte
+	depth  int   ///< number of objects and arrays currently open
+	err    error ///< sticky error returned by every later call to Next
+}
+
+// /**
+// * @brief Creates a Tokenizer reading strict JSON from r.
+// *
+// * @details A *bufio.Reader is used directly; any other reader is wrapped in one. Short reads are
+// * fine: Next blocks until a whole token has arrived.
+// *
+// * @param r The reader supplying the JSON text.
+// * @return The new Tokenizer.
+// */
+func NewTokenizer(r io.Reader) *Tokenizer {
+	return NewTokenizerWithOptions(r, ParseOptions{})
+}
+
+// /**
+// * @brief Creates a Tokenizer reading from r, accepting the grammar extensions enabled in opts.
+// *
+// * @param r The reader supplying the JSON text.
+// * @param opts The parse options selecting grammar extensions.
+// * @return The new Tokenizer.
+// */
+func NewTokenizerWithOptions(r io.Reader, opts ParseOptions) *Tokenizer {
+	return &Tokenizer{r: bufio.NewReader(r), opts: &opts, line: 1, col: 1}
+}
+
+// /**
+// * @brief Returns the next token from the stream.
+// *
+// * @details At the end of the input Next returns io.EOF if every object and array was closed, and a
+// * *SyntaxError if the stream stopped in the middle of a token or value. Read errors other than io.EOF
+// * are returned as they are. Once Next has failed, it keeps returning the same error.
+// *
+// * @return The token, or an error.
+// */
+func (t *Tokenizer) Next() (Token, error) {
+	if t.err != nil {
+		return Token{}, t.err
+	}
+	token, err := t.next()
+	if err != nil {
+		t.err = err
+	}
+	return token, err
+}
+
+// /**
+// * @brief Reads and decodes one token.
+// *
+// * @return The token, or an error.
+// */
+func (t *Tokenizer) next() (Token, error) {
+	char, err := t.skipWhitespace()
+	if err == io.EOF && t.depth > 0 {
+		return Token{}, &SyntaxError{Msg: fmt.Sprintf("unexpected end of input at line %d, col %d", t.line, t.col), Offset: t.offset}
+	}
+	if err != nil {
+		return Token{}, err
+	}
+
+	start, line, col := t.offset, t.line, t.col
+	var buf []byte
+	switch char {
+	case '{', '[':
+		t.depth++
+		buf = []byte{t.readByte()}
+	case '}', ']':
+		t.depth--
+		buf = []byte{t.readByte()}
+	case ':', ',':
+		buf = []byte{t.readByte()}
+	case '"':
+		buf, err = t.readString()
+	case '/':
+		if !t.opts.AllowComments {
+			buf, err = t.readWord()
+			break
+		}
+		buf, err = t.readComment()
+		if err == io.EOF {
+			return Token{}, &SyntaxError{Msg: fmt.Sprintf("unterminated comment at line %d, col %d", line, col), Offset: start}
+		}
+	default:
+		buf, err = t.readWord()
+	}
+	if err == io.EOF {
+		return Token{}, &SyntaxError{Msg: fmt.Sprintf("unexpected end of input in token at line %d, col %d", line, col), Offset: start}
+	}
+	if err != nil {
+		return Token{}, err
+	}
+
+	/// Decode the framed bytes with the same scanner the in-memory tokenizer uses.
+	token, end, err := scanToken(newLineCounter(string(buf)), 0, t.opts)
+	if err != nil || end != len(buf) {
+		return Token{}, &SyntaxError{Msg: fmt.Sprintf("invalid token at line %d, col %d: %q", line, col, buf), Offset: start}
+	}
+	token.Offset, token.End, token.Line, token.Column = start, t.offset, line, col
+	return token, nil
+}
+
+// /**
+// * @brief Skips whitespace and peeks at the first byte of the next token without consuming it.
+// *
+// * @return The next byte, or the read error.
+// */
+func (t *Tokenizer) skipWhitespace() (byte, error) {
+	for {
+		b, err := t.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			t.r.UnreadByte()
+			return b, nil
+		}
+		t.advance(b)
+	}
+}
+
+// /**
+// * @brief Consumes a byte already known to be buffered.
+// */
+func (t *Tokenizer) readByte() byte {
+	b, _ := t.r.ReadByte()
+	t.advance(b)
+	return b
+}
+
+// /**
+// * @brief Moves the offset, line and column past a consumed byte.
+// *
+// * @param b The byte consumed.
+// */
+func (t *Tokenizer) advance(b byte) {
+	t.offset++
+	switch {
+	case b == '\n':
+		t.line++
+		t.col = 1
+	case utf8.RuneStart(b):
+		t.col++
+	}
+}
+
+// /**
+// * @brief Reads a string literal, quotes included, up to its unescaped closing quote.
+// *
+// * @return The raw bytes of the literal, or the read error.
+// */
+func (t *Tokenizer) readString() ([]byte, error) {
+	buf := []byte{t.readByte()}
+	escaped := false
+	for {
+		b, err := t.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		t.advance(b)
+		buf = append(buf, b)
+		switch {
+		case escaped:
+			escaped = false
+		case b == '\\':
+			escaped = true
+		case b == '"':
+			return buf, nil
+		}
+	}
+}
+
+// /**
+// * @brief Reads a '//' line comment up to its newline, or a '/* */' block comment through its '*/'.
+// *
+// * @details End of input ends a line comment normally; inside a block comment it is io.EOF. Anything
+// * else after the first '/' is returned as is for scanToken to reject.
+// *
+// * @return The raw bytes of the comment, delimiters included, or the read error.
+// */
+func (t *Tokenizer) readComment() ([]byte, error) {
+	buf := []byte{t.readByte()}
+	kind, err := t.r.ReadByte()
+	if err == io.EOF {
+		return buf, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.advance(kind)
+	buf = append(buf, kind)
+	if kind != '/' && kind != '*' {
+		return buf, nil
+	}
+	for {
+		b, err := t.r.ReadByte()
+		if err == io.EOF && kind != '*' {
+			return buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if kind == '/' && b == '\n' {
+			t.r.UnreadByte()
+			return buf, nil
+		}
+		t.advance(b)
+		buf = append(buf, b)
+		if kind == '*' && len(buf) >= 4 && b == '/' && buf[len(buf)-2] == '*' {
+			return buf, nil
+		}
+	}
+}
+
+// /**
+// * @brief Reads a number or literal: everything up to the next whitespace or structural character.
+// *
+// * @details End of input after at least one byte ends the word normally. Words are numbers or short
+// * literals, so one longer than opts.MaxNumberLen, when set, is rejected before the rest of it is read.
+// *
+// * @return The raw bytes of the word, or the read error.
+// */
+func (t *Tokenizer) readWord() ([]byte, error) {
+	line, col, start := t.line, t.col, t.offset
+	buf := []byte{t.readByte()}
+	for {
+		if limit := t.opts.MaxNumberLen; limit > 0 && len(buf) > limit {
+			return nil, &SyntaxError{Msg: fmt.Sprintf("number literal at line %d, col %d is longer than %d bytes", line, col, limit), Offset: start}
+		}
+		b, err := t.r.ReadByte()
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.IndexByte(" \t\n\r{}[]:,\"", b) >= 0 {
+			t.r.UnreadByte()
+			return buf, nil
+		}
+		t.advance(b)
+		buf = append(buf, b)
+	}
+}
+
+// /**
+// * @brief Counts the elements of a top-level JSON array without building them.
+// *
+// * @details The stream is tokenized up to the array's closing bracket and only elements directly inside
+// * it are counted; nested objects and arrays are skipped by tracking bracket depth. Tokens are checked
+// * one by one but the array's structure is not fully validated, and anything after the closing bracket
+// * is not tokenized. Useful for sizing progress bars or shards before a full parse.
+// *
+// * @param r The reader supplying the JSON text.
+// * @return The number of elements, or an error if the input is not an array or is malformed.
+// */
+func CountArrayElements(r io.Reader) (int, error) {
+	t := NewTokenizer(r)
+	token, err := t.Next()
+	if err == io.EOF {
+		return 0, fmt.Errorf("empty input")
+	}
+	if err != nil {
+		return 0, err
+	}
+	if token.Type != TokenArrayStart {
+		return 0, fmt.Errorf("expected '[' at line %d, col %d", token.Line, token.Column)
+	}
+	count, depth := 0, 1
+	for depth > 0 {
+		if token, err = t.Next(); err != nil {
+			return 0, err
+		}
+		switch token.Type {
+		case TokenComma, TokenColon:
+			continue
+		case TokenObjectEnd, TokenArrayEnd:
+			depth--
+			continue
+		}
+		/// Anything else starts a value; only those directly inside the top-level array count.
+		if depth == 1 {
+			count++
+		}
+		if token.Type == TokenObjectStart || token.Type == TokenArrayStart {
+			depth++
+		}
+	}
+	return count, nil
+}
+
+// / Decoder reads a sequence of JSON values from a stream, building each one as it arrives without
+// / holding the whole input in memory. Values may be concatenated or separated by whitespace, as in
+// / newline-delimited JSON.
+type Decoder struct {
+	ts  *TokenStream
+	err error ///< sticky error returned by every later call to Decode
+}
+
+// /**
+// * @brief Creates a Decoder reading strict JSON from r.
+// *
+// * @param r The reader supplying the JSON text.
+// * @return The new Decoder.
+// */
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithOptions(r, ParseOptions{})
+}
+
+// /**
+// * @brief Creates a Decoder reading from r with the given parse options.
+// *
+// * @details Limits, duplicate-key checks and the grammar extensions the streaming Tokenizer supports are
+// * applied to every value. OnTrailingData is ignored, since following values are what Decode reads next.
+// *
+// * @param r The reader supplying the JSON text.
+// * @param opts The parse options.
+// * @return The new Decoder.
+// */
+func NewDecoderWithOptions(r io.Reader, opts ParseOptions) *Decoder {
+	t := NewTokenizerWithOptions(r, opts)
+	return &Decoder{ts: &TokenStream{opts: t.opts, source: t}}
+}
+
+// /**
+// * @brief Reads and returns the next JSON value from the stream.
+// *
+// * @details The input is read only as far as the end of the value, so Decode can be used on a connection
+// * that stays open between values. Once Decode has failed, it keeps returning the same error.
+// *
+// * @return The value, io.EOF when the stream ends cleanly between values, or an error.
+// */
+func (d *Decoder) Decode() (interface{}, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	ts := d.ts
+	if ts.Peek().Type == TokenEOF && ts.err == nil {
+		d.err = io.EOF
+		return nil, d.err
+	}
+	value, err := parseValue(ts)
+	switch {
+	case ts.err != nil:
+		/// Report what stopped the tokenizer rather than the parser's view of the early end.
+		err = ts.err
+	case err == nil && len(ts.duplicates) > 0:
+		err = &DuplicateKeyError{Paths: ts.duplicates}
+	}
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	return value, nil
+}
+
+// /**
+// * @brief Parses newline-delimited JSON (JSON Lines): one independent value per line.
+// *
+// * @details Each non-blank line is parsed on its own with ParseJSON; blank lines are skipped. Lines are
+// * read whole, however long, so a single value must not span lines.
+// *
+// * @param r The reader supplying the NDJSON text.
+// * @return The values in line order, or an error naming the 1-based line that failed.
+// */
+func ParseNDJSON(r io.Reader) ([]interface{}, error) {
+	br := bufio.NewReader(r)
+	var values []interface{}
+	for line := 1; ; line++ {
+		text, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if strings.TrimSpace(text) != "" {
+			value, parseErr := ParseJSON(text)
+			if parseErr != nil {
+				return nil, fmt.Errorf("line %d: %w", line, parseErr)
+			}
+			values = append(values, value)
+		}
+		if err == io.EOF {
+			return values, nil
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package jsonparser
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// chunkedReader returns s through a pipe a few bytes per write, so every
+// read blocks until the next chunk arrives, as on a slow socket.
+func chunkedReader(s string, size int) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		for len(s) > 0 {
+			n := min(size, len(s))
+			pw.Write([]byte(s[:n]))
+			s = s[n:]
+		}
+		pw.Close()
+	}()
+	return pr
+}
+
+func TestTokenizer(t *testing.T) {
+	readers := []struct {
+		name string
+		open func(s string) io.Reader
+	}{
+		{"whole", func(s string) io.Reader { return strings.NewReader(s) }},
+		{"one byte reads", func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) }},
+		{"half reads", func(s string) io.Reader { return iotest.HalfReader(strings.NewReader(s)) }},
+		{"blocking chunks", func(s string) io.Reader { return chunkedReader(s, 3) }},
+	}
+	tests := []struct {
+		name    string
+		input   string
+		opts    ParseOptions
+		wantErr string // error after the tokens ("" for a clean io.EOF)
+	}{
+		{"complete value", `{"name": "é\n", "list": [1, -2.5e3, true, false, null]}`, ParseOptions{}, ""},
+		{"values across lines", "[\n  \"a\",\n  {\"b\": 12345678901234567890}\n]\n", ParseOptions{}, ""},
+		{"bare scalar", ` 42 `, ParseOptions{}, ""},
+		{"comments", "// c\n[1 /* x */]", ParseOptions{AllowComments: true}, ""},
+		{"empty input", "", ParseOptions{}, ""},
+		{"eof inside a string", `{"a": "abc`, ParseOptions{}, "unexpected end of input in token at line 1, col 7"},
+		{"eof inside an object", `{"a": [1, 2]`, ParseOptions{}, "unexpected end of input at line 1, col 13"},
+		{"eof inside a comment", "[1 /* x", ParseOptions{AllowComments: true}, "unterminated comment"},
+		{"invalid literal", `[tru]`, ParseOptions{}, `invalid token at line 1, col 2: "tru"`},
+	}
+	for _, r := range readers {
+		for _, tt := range tests {
+			t.Run(r.name+"/"+tt.name, func(t *testing.T) {
+				tz := NewTokenizerWithOptions(r.open(tt.input), tt.opts)
+				var got []Token
+				var err error
+				for {
+					var token Token
+					if token, err = tz.Next(); err != nil {
+						break
+					}
+					got = append(got, token)
+				}
+				if tt.wantErr == "" {
+					if err != io.EOF {
+						t.Fatalf("ended with %v, want io.EOF", err)
+					}
+					want, _ := TokenizeWithOptions(tt.input, tt.opts)
+					want = want[:len(want)-1] // the in-memory tokenizer ends with TokenEOF
+					if len(want) == 0 {
+						want = nil
+					}
+					if !reflect.DeepEqual(got, want) {
+						t.Errorf("tokens\n%+v\nwant\n%+v", got, want)
+					}
+					return
+				}
+				var syntax *SyntaxError
+				if !errors.As(err, &syntax) {
+					t.Fatalf("ended with %v, want a *SyntaxError", err)
+				}
+				checkErr(t, err, tt.wantErr)
+				if _, again := tz.Next(); again != err {
+					t.Errorf("Next after the error returned %v", again)
+				}
+			})
+		}
+	}
+}
+
+func TestTokenizerReadError(t *testing.T) {
+	broken := errors.New("connection reset")
+	tz := NewTokenizer(io.MultiReader(strings.NewReader(`[1, `), iotest.ErrReader(broken)))
+	var err error
+	for err == nil {
+		_, err = tz.Next()
+	}
+	if err != broken {
+		t.Errorf("ended with %v, want the read error", err)
+	}
+}
+
+func TestCountArrayElements(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr string
+	}{
+		{"empty array", `[]`, 0, ""},
+		{"flat array", `[1, "a", true, null]`, 4, ""},
+		{"nested arrays don't inflate the count", `[[1, 2, 3], [[4], [5, [6, 7]]], []]`, 3, ""},
+		{"objects with commas inside", `[{"a": 1, "b": [1, 2]}, {"c": {"d": 3, "e": 4}}]`, 2, ""},
+		{"brackets inside strings", `["[", "]]", "{,}", "\"["]`, 4, ""},
+		{"whitespace and newlines", "[\n  1 ,\n  2\n]\n", 2, ""},
+		{"trailing data isn't read", `[1, 2] garbage`, 2, ""},
+		{"empty input", "  ", 0, "empty input"},
+		{"not an array", `{"a": [1, 2]}`, 0, "expected '[' at line 1, col 1"},
+		{"unterminated", `[1, [2, 3]`, 0, "unexpected end of input"},
+		{"invalid token", `[1, tru]`, 0, "invalid token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CountArrayElements(iotest.HalfReader(strings.NewReader(tt.input)))
+			checkErr(t, err, tt.wantErr)
+			if got != tt.want {
+				t.Errorf("count = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package jsonparser
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// / typeShape accumulates every kind of value observed at one position of a document.
+type typeShape struct {
+	strings, numbers, bools, nulls, objects, arrays bool
+	fractional                                      bool                  ///< a non-integral number was seen
+	fields                                          map[string]*typeShape ///< merged members of all objects seen
+	elem                                            *typeShape            ///< merged elements of all arrays seen
+}
+
+// /**
+// * @brief Records a value into the shape, merging objects and arrays recursively.
+// *
+// * @param v The JSON value observed at this position.
+// */
+func (s *typeShape) observe(v interface{}) {
+	switch vv := untypedSlice(v).(type) {
+	case map[string]interface{}:
+		s.objects = true
+		if s.fields == nil {
+			s.fields = make(map[string]*typeShape)
+		}
+		for k, val := range vv {
+			field, ok := s.fields[k]
+			if !ok {
+				field = &typeShape{}
+				s.fields[k] = field
+			}
+			field.observe(val)
+		}
+	case []interface{}:
+		s.arrays = true
+		if s.elem == nil {
+			s.elem = &typeShape{}
+		}
+		for _, val := range vv {
+			s.elem.observe(val)
+		}
+	case string:
+		s.strings = true
+	case float64:
+		s.numbers = true
+		if vv != math.Trunc(vv) {
+			s.fractional = true
+		}
+	case int64:
+		s.numbers = true
+	case bool:
+		s.bools = true
+	case nil:
+		s.nulls = true
+	}
+}
+
+// /**
+// * @brief Writes the Go type expression for the shape.
+// *
+// * @details Positions that saw exactly one kind of value map to the matching Go type. Anything
+// * mixed, null, or never observed becomes interface{}.
+// *
+// * @param sb The builder receiving the type expression.
+// */
+func (s *typeShape) writeType(sb *strings.Builder) {
+	kinds := 0
+	for _, seen := range []bool{s.strings, s.numbers, s.bools, s.nulls, s.objects, s.arrays} {
+		if seen {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		sb.WriteString("interface{}")
+		return
+	}
+	switch {
+	case s.strings:
+		sb.WriteString("string")
+	case s.numbers && s.fractional:
+		sb.WriteString("float64")
+	case s.numbers:
+		sb.WriteString("int")
+	case s.bools:
+		sb.WriteString("bool")
+	case s.arrays:
+		sb.WriteString("[]")
+		s.elem.writeType(sb)
+	case s.objects:
+		s.writeStruct(sb)
+	default:
+		sb.WriteString("interface{}")
+	}
+}
+
+// /**
+// * @brief Writes a struct type with one field per observed key, in sorted key order.
+// *
+// * @param sb The builder receiving the struct definition.
+// */
+func (s *typeShape) writeStruct(sb *strings.Builder) {
+	keys := make([]string, 0, len(s.fields))
+	for k := range s.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	used := make(map[string]bool)
+	sb.WriteString("struct {\n")
+	for _, k := range keys {
+		name := goFieldName(k)
+		/// Disambiguate keys that map to the same identifier.
+		for i := 2; used[name]; i++ {
+			name = goFieldName(k) + strconv.Itoa(i)
+		}
+		used[name] = true
+
+		sb.WriteString(name + " ")
+		s.fields[k].writeType(sb)
+		sb.WriteString(" " + structTag(k) + "\n")
+	}
+	sb.WriteString("}")
+}
+
+// /**
+// * @brief Writes the struct tag literal mapping a field to its JSON key.
+// *
+// * @details Tags are raw strings like `json:"name"`. A key containing a backtick can't appear in a raw
+// * string, so its tag is written as an interpreted string literal instead.
+// *
+// * @param key The JSON object key.
+// * @return The tag literal, delimiters included.
+// */
+func structTag(key string) string {
+	tag := "json:" + strconv.Quote(key)
+	if strings.ContainsRune(tag, '`') {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// / commonInitialisms are written in upper case when they form a whole word of a field name.
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// /**
+// * @brief Converts a JSON key into an exported Go identifier.
+// *
+// * @details Splits the key on anything that isn't a letter or digit, title-cases each word and
+// * upper-cases common initialisms, so "user_id" becomes "UserID".
+// *
+// * @param key The JSON object key.
+// * @return An exported Go identifier.
+// */
+func goFieldName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	name := sb.String()
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		/// Identifiers can't start with a digit.
+		name = "X" + name
+	}
+	return name
+}
+
+// /**
+// * @brief Generates Go source for a type that can hold the given document.
+// *
+// * @details Objects become structs with `json` tags, nested objects become nested structs and arrays
+// * merge all of their elements to infer the element type. Numbers are int unless a fractional value
+// * was seen. Null, mixed or unobserved positions become interface{}.
+// *
+// * @param root The parsed JSON value to infer the type from.
+// * @param typeName The name of the generated top-level type.
+// * @return The gofmt-formatted type declaration or an error.
+// */
+func GenerateStruct(root interface{}, typeName string) (string, error) {
+	if !token.IsIdentifier(typeName) {
+		return "", fmt.Errorf("invalid type name %q", typeName)
+	}
+	shape := &typeShape{}
+	shape.observe(root)
+
+	var sb strings.Builder
+	sb.WriteString("type " + typeName + " ")
+	shape.writeType(&sb)
+	sb.WriteString("\n")
+
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return "", fmt.Errorf("generated invalid source: %v", err)
+	}
+	return string(src), nil
+}
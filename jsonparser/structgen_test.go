@@ -0,0 +1,93 @@
+package jsonparser
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// typeCheck compiles src, a generated type declaration, and returns the json tag of every struct
+// field it declares.
+func typeCheck(t *testing.T, src string) []string {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "gen.go", "package gen\n\n"+src, 0)
+	if err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, src)
+	}
+	if _, err := (&types.Config{}).Check("gen", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("generated source does not compile: %v\n%s", err, src)
+	}
+	var tags []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if field, ok := n.(*ast.Field); ok && field.Tag != nil {
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				t.Fatalf("bad tag literal %s", field.Tag.Value)
+			}
+			tags = append(tags, reflect.StructTag(tag).Get("json"))
+		}
+		return true
+	})
+	return tags
+}
+
+func TestGenerateStruct(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     []string // lines the generated source must contain
+		wantTags []string
+	}{
+		{
+			name:     "nested document",
+			input:    `{"user_id": 1, "name": "Ann", "score": 1.5, "active": true, "address": {"city": "Paris"}, "tags": ["a"], "extra": null}`,
+			want:     []string{"UserID int", "Name string", "Score float64", "Active bool", "City string", "Tags []string", "Extra interface{}"},
+			wantTags: []string{"active", "address", "city", "extra", "name", "score", "tags", "user_id"},
+		},
+		{
+			name:     "array of objects merges members",
+			input:    `[{"id": 1}, {"id": 2, "note": "x"}, {"id": "three"}]`,
+			want:     []string{"type Doc []struct", "ID interface{}", "Note string"},
+			wantTags: []string{"id", "note"},
+		},
+		{
+			name:     "keys that need care",
+			input:    "{\"a`b\": 1, \"2fa\": true, \"a-b\": 1, \"a_b\": 2, \"\": 0}",
+			want:     []string{"X2fa bool", "AB int", "AB2 int", "Field int"},
+			wantTags: []string{"", "2fa", "a-b", "a_b", "a`b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseJSON(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			src, err := GenerateStruct(doc, "Doc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(strings.Join(strings.Fields(src), " "), want) {
+					t.Errorf("source lacks %q:\n%s", want, src)
+				}
+			}
+			tags := typeCheck(t, src)
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags %q, want %q", tags, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestGenerateStructRejectsBadTypeName(t *testing.T) {
+	if _, err := GenerateStruct(map[string]interface{}{}, "not a name"); err == nil {
+		t.Error("expected an error for an invalid type name")
+	}
+}
@@ -0,0 +1,147 @@
+package jsonparser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// /**
+// * @brief Splits an array of objects into rows and the sorted union of their keys.
+// *
+// * @param v The JSON value to inspect.
+// * @return The rows, the column names, and an error if v is not an array of objects.
+// */
+func tableRows(v interface{}) ([]map[string]interface{}, []string, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("not table-like: expected an array of objects, got %s", valueKind(v))
+	}
+	rows := make([]map[string]interface{}, len(arr))
+	seen := make(map[string]bool)
+	var columns []string
+	for i, elem := range arr {
+		obj, ok := elem.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("not table-like: element %d is %s, not an object", i, valueKind(elem))
+		}
+		for k := range obj {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+		rows[i] = obj
+	}
+	sort.Strings(columns)
+	return rows, columns, nil
+}
+
+// /**
+// * @brief Formats a scalar for a table cell. Strings are written without quotes.
+// */
+func scalarCell(v interface{}) string {
+	switch vv := v.(type) {
+	case string:
+		return vv
+	case nil:
+		return "null"
+	case float64:
+		return FormatNumber(vv)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// /**
+// * @brief Renders an array of flat objects as a Markdown table.
+// *
+// * @details There is a column for every key found in any element, in sorted order, and a row per
+// * element. A key an element doesn't have leaves an empty cell; an explicit null is written as null.
+// * Pipes and newlines inside values are escaped so they can't break the table.
+// *
+// * @param v The JSON value to render.
+// * @return The Markdown table, or an error if v is not an array of objects with scalar values.
+// */
+func RenderMarkdownTable(v interface{}) (string, error) {
+	rows, columns, err := tableRows(v)
+	if err != nil {
+		return "", err
+	}
+	if len(columns) == 0 {
+		return "", fmt.Errorf("not table-like: no keys to use as columns")
+	}
+
+	cell := strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		sb.WriteString("|")
+		for _, c := range cells {
+			sb.WriteString(" " + cell.Replace(c) + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(columns)
+	sb.WriteString(strings.Repeat("| --- ", len(columns)) + "|\n")
+	for i, row := range rows {
+		cells := make([]string, len(columns))
+		for j, col := range columns {
+			val, ok := row[col]
+			if !ok {
+				continue
+			}
+			switch val.(type) {
+			case map[string]interface{}, []interface{}:
+				return "", fmt.Errorf("not table-like: element %d has nested %s at key %q", i, valueKind(val), col)
+			}
+			cells[j] = scalarCell(val)
+		}
+		writeRow(cells)
+	}
+	return sb.String(), nil
+}
+
+// /**
+// * @brief Writes an array of flat objects as CSV.
+// *
+// * @details The header row is the sorted union of keys across all elements, followed by a record per
+// * element. A missing key leaves an empty cell and null is written as null. Nested objects and arrays
+// * are written as compact JSON text in their cell. Quoting is handled by encoding/csv.
+// *
+// * @param v The JSON value to export.
+// * @param w The writer receiving the CSV.
+// * @return An error if v is not an array of objects, or the first write error.
+// */
+func RenderCSV(v interface{}, w io.Writer) error {
+	rows, columns, err := tableRows(v)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for j, col := range columns {
+			val, ok := row[col]
+			if !ok {
+				continue
+			}
+			switch val.(type) {
+			case map[string]interface{}, []interface{}:
+				record[j] = compactString(val)
+			default:
+				record[j] = scalarCell(val)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,63 @@
+package jsonparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"missing key leaves an empty cell", `[{"id": 1, "name": "Ann", "age": 30}, {"id": 2, "name": "Bob"}, {"id": 3, "age": null, "name": "Cy"}]`,
+			"| age | id | name |\n| --- | --- | --- |\n| 30 | 1 | Ann |\n|  | 2 | Bob |\n| null | 3 | Cy |\n", ""},
+		{"pipes are escaped", `[{"expr": "a | b", "k|ey": "x"}]`,
+			"| expr | k\\|ey |\n| --- | --- |\n| a \\| b | x |\n", ""},
+		{"newlines become line breaks", `[{"text": "one\ntwo\r\nthree"}]`,
+			"| text |\n| --- |\n| one<br>two<br>three |\n", ""},
+		{"scalars", `[{"b": true, "f": 1.5, "s": ""}]`,
+			"| b | f | s |\n| --- | --- | --- |\n| true | 1.5 |  |\n", ""},
+		{"not an array", `{"a": 1}`, "", "not table-like: expected an array of objects, got object"},
+		{"element not an object", `[{"a": 1}, 2]`, "", "not table-like: element 1 is number, not an object"},
+		{"nested value", `[{"a": 1}, {"a": [1]}]`, "", `not table-like: element 1 has nested array at key "a"`},
+		{"no keys", `[{}, {}]`, "", "not table-like: no keys to use as columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderMarkdownTable(mustParse(t, tt.input))
+			checkErr(t, err, tt.wantErr)
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"comma and quote are quoted", `[{"name": "Doe, \"JD\" John", "id": 1}]`,
+			"id,name\n1,\"Doe, \"\"JD\"\" John\"\n", ""},
+		{"newline is quoted", `[{"a": "x\ny"}]`, "a\n\"x\ny\"\n", ""},
+		{"missing key and null", `[{"a": 1, "b": null}, {"b": 2}]`, "a,b\n1,null\n,2\n", ""},
+		{"nested values as compact JSON", `[{"a": {"x": [1, "y"]}}]`, "a\n\"{\"\"x\"\":[1,\"\"y\"\"]}\"\n", ""},
+		{"not an array", `"x"`, "", "not table-like: expected an array of objects, got string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := RenderCSV(mustParse(t, tt.input), &sb)
+			checkErr(t, err, tt.wantErr)
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package jsonparser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// /**
+// * @brief Checks a document against an example document whose values may be wildcards.
+// *
+// * @details The template is an ordinary parsed JSON value in which the string "*" matches any value and
+// * a type placeholder such as "<number>" matches any value of that type ("<string>", "<number>",
+// * "<boolean>", "<null>", "<object>" or "<array>"). Every other value must be Equal, so numbers match by
+// * value whether they are float64 or int64 (from ParseOptions.PreserveIntegers). Objects must have
+// * exactly the template's keys and arrays exactly its length, element by element.
+// *
+// * @param doc The parsed document to check.
+// * @param template The parsed template.
+// * @return Whether the document matches, and the sorted JSON Pointers of every mismatch.
+// */
+func MatchTemplate(doc, template interface{}) (bool, []string) {
+	var mismatches []string
+	matchTemplate("", doc, template, &mismatches)
+	sort.Strings(mismatches)
+	return len(mismatches) == 0, mismatches
+}
+
+// /**
+// * @brief Compares one value against its template, recording the pointer of every mismatch.
+// *
+// * @param pointer The JSON Pointer of doc.
+// * @param doc The value being checked.
+// * @param template The template for it.
+// * @param mismatches The mismatch pointers collected so far.
+// */
+func matchTemplate(pointer string, doc, template interface{}, mismatches *[]string) {
+	doc, template = untypedSlice(doc), untypedSlice(template)
+	switch t := template.(type) {
+	case string:
+		if t == "*" {
+			return
+		}
+		if strings.HasPrefix(t, "<") && strings.HasSuffix(t, ">") && isKindName(t[1:len(t)-1]) {
+			if valueKind(doc) != t[1:len(t)-1] {
+				*mismatches = append(*mismatches, pointer)
+			}
+			return
+		}
+	case map[string]interface{}:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			*mismatches = append(*mismatches, pointer)
+			return
+		}
+		for key, val := range t {
+			child := pointer + "/" + escapePointerToken(key)
+			if docVal, ok := obj[key]; ok {
+				matchTemplate(child, docVal, val, mismatches)
+			} else {
+				*mismatches = append(*mismatches, child)
+			}
+		}
+		for key := range obj {
+			if _, ok := t[key]; !ok {
+				*mismatches = append(*mismatches, pointer+"/"+escapePointerToken(key))
+			}
+		}
+		return
+	case []interface{}:
+		arr, ok := doc.([]interface{})
+		if !ok || len(arr) != len(t) {
+			*mismatches = append(*mismatches, pointer)
+			return
+		}
+		for i := range t {
+			matchTemplate(pointer+"/"+strconv.Itoa(i), arr[i], t[i], mismatches)
+		}
+		return
+	}
+	if !Equal(doc, template) {
+		*mismatches = append(*mismatches, pointer)
+	}
+}
+
+// /**
+// * @brief Reports whether name is one of the JSON type names used by valueKind.
+// *
+// * @param name The candidate type name.
+// * @return True for "object", "array", "string", "number", "boolean" and "null".
+// */
+func isKindName(name string) bool {
+	switch name {
+	case "object", "array", "string", "number", "boolean", "null":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package jsonparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		template string
+		opts     ParseOptions
+		want     []string
+	}{
+		{"exact", `{"a": 1, "b": [true]}`, `{"a": 1, "b": [true]}`, ParseOptions{}, nil},
+		{"wildcard", `{"a": {"deep": 1}}`, `{"a": "*"}`, ParseOptions{}, nil},
+		{"type placeholder", `{"a": 1, "b": "x"}`, `{"a": "<number>", "b": "<number>"}`, ParseOptions{}, []string{"/b"}},
+		{"missing and extra keys", `{"a": 1, "c": 3}`, `{"a": 1, "b": 2}`, ParseOptions{}, []string{"/b", "/c"}},
+		{"array length", `[1, 2]`, `[1]`, ParseOptions{}, []string{""}},
+		{"preserved integer equals template number", `{"id": 42}`, `{"id": 42}`, ParseOptions{PreserveIntegers: true}, nil},
+		{"preserved integer differs", `{"id": 43}`, `{"id": 42}`, ParseOptions{PreserveIntegers: true}, []string{"/id"}},
+		{"preserved integer as number placeholder", `{"id": 42}`, `{"id": "<number>"}`, ParseOptions{PreserveIntegers: true}, nil},
+		{"every type placeholder", `{"s": "x", "n": 1.5, "b": false, "z": null, "o": {}, "a": []}`,
+			`{"s": "<string>", "n": "<number>", "b": "<boolean>", "z": "<null>", "o": "<object>", "a": "<array>"}`, ParseOptions{}, nil},
+		{"every type placeholder mismatched", `{"s": 1, "n": "1", "b": null, "z": 0, "o": [], "a": {}}`,
+			`{"s": "<string>", "n": "<number>", "b": "<boolean>", "z": "<null>", "o": "<object>", "a": "<array>"}`, ParseOptions{},
+			[]string{"/a", "/b", "/n", "/o", "/s", "/z"}},
+		{"unknown placeholder is a literal", `{"a": "<date>"}`, `{"a": "<date>"}`, ParseOptions{}, nil},
+		{"unknown placeholder doesn't match other strings", `{"a": "2024-01-01"}`, `{"a": "<date>"}`, ParseOptions{}, []string{"/a"}},
+		{"wildcard matches null but not a missing key", `{"a": null}`, `{"a": "*", "b": "*"}`, ParseOptions{}, []string{"/b"}},
+		{"wildcard array elements", `[1, "x", {"k": 1}]`, `["*", "*", {"k": "<number>"}]`, ParseOptions{}, nil},
+		{"object where an array is expected", `{"a": {}}`, `{"a": []}`, ParseOptions{}, []string{"/a"}},
+		{"mismatches inside arrays", `{"items": [{"id": 1, "name": 2}, {"id": "x", "name": "b"}]}`,
+			`{"items": [{"id": "<number>", "name": "<string>"}, {"id": "<number>", "name": "<string>"}]}`, ParseOptions{},
+			[]string{"/items/0/name", "/items/1/id"}},
+		{"escaped keys in mismatch paths", `{"a/b": 1, "c~d": 2}`, `{"a/b": "<string>", "c~d": 2}`, ParseOptions{}, []string{"/a~1b"}},
+		{"API response matches", `{"id": 17, "token": "f3a9", "user": {"name": "ann", "admin": false}, "roles": ["read"]}`,
+			`{"id": "<number>", "token": "*", "user": {"name": "ann", "admin": "<boolean>"}, "roles": ["read"]}`, ParseOptions{}, nil},
+		{"API response doesn't match", `{"id": "17", "token": null, "user": {"name": "bob"}, "roles": ["read", "write"]}`,
+			`{"id": "<number>", "token": "*", "user": {"name": "ann", "admin": "<boolean>"}, "roles": ["read"]}`, ParseOptions{},
+			[]string{"/id", "/roles", "/user/admin", "/user/name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseJSONWithOptions(tt.doc, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, got := MatchTemplate(doc, mustParse(t, tt.template))
+			if ok != (len(tt.want) == 0) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchTemplate = %v, %v; want mismatches %v", ok, got, tt.want)
+			}
+		})
+	}
+}
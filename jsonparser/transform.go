@@ -0,0 +1,190 @@
+package jsonparser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// /**
+// * @brief Replaces every whitespace-only string in the tree with null.
+// *
+// * @details A common ETL cleaning step for sources that treat "   " like a missing value. This is lossy:
+// * the original blank strings can't be recovered from the result. Empty strings count as blank. Object
+// * keys are left alone. The input is not modified; a transformed copy is returned.
+// *
+// * @param v The JSON value to clean.
+// * @return A copy of v with blank strings replaced by nil.
+// */
+func CoalesceBlankStrings(v interface{}) interface{} {
+	switch vv := untypedSlice(v).(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(vv))
+		for k, val := range vv {
+			obj[k] = CoalesceBlankStrings(val)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(vv))
+		for i, val := range vv {
+			arr[i] = CoalesceBlankStrings(val)
+		}
+		return arr
+	case string:
+		if strings.TrimSpace(vv) == "" {
+			return nil
+		}
+		return vv
+	}
+	return v
+}
+
+// /**
+// * @brief Truncates long arrays so huge documents can be printed or viewed quickly.
+// *
+// * @details Every array with more than maxArrayItems elements keeps its first maxArrayItems elements,
+// * followed by a marker string such as "... (99990 more)". Shorter arrays are copied as-is. Nested
+// * arrays are truncated too. The input is not modified. A maxArrayItems below zero disables truncation.
+// *
+// * @param v The JSON value to preview.
+// * @param maxArrayItems The number of elements to keep from each array.
+// * @return A copy of v with long arrays truncated.
+// */
+func Preview(v interface{}, maxArrayItems int) interface{} {
+	switch vv := untypedSlice(v).(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(vv))
+		for k, val := range vv {
+			obj[k] = Preview(val, maxArrayItems)
+		}
+		return obj
+	case []interface{}:
+		n := len(vv)
+		if maxArrayItems >= 0 && n > maxArrayItems {
+			n = maxArrayItems
+		}
+		arr := make([]interface{}, n, n+1)
+		for i := range arr {
+			arr[i] = Preview(vv[i], maxArrayItems)
+		}
+		if n < len(vv) {
+			arr = append(arr, fmt.Sprintf("... (%d more)", len(vv)-n))
+		}
+		return arr
+	}
+	return v
+}
+
+// /**
+// * @brief Recursively sorts every array so documents can be compared ignoring array order.
+// *
+// * @details Elements are ordered by their canonical serialization (compact JSON with sorted keys), after
+// * their own nested arrays have been sorted. Two arrays holding the same elements in different orders
+// * therefore come out identical, so Equal on the results is an order-insensitive comparison. Only use
+// * this when array order carries no meaning, e.g. arrays used as sets: the original order is lost. The
+// * input is not modified; a sorted copy is returned.
+// *
+// * @param v The JSON value to sort.
+// * @return A copy of v with every array sorted.
+// */
+func SortArrays(v interface{}) interface{} {
+	switch vv := untypedSlice(v).(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(vv))
+		for k, val := range vv {
+			obj[k] = SortArrays(val)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(vv))
+		keys := make([]string, len(vv))
+		for i, val := range vv {
+			arr[i] = SortArrays(val)
+			keys[i] = compactString(arr[i])
+		}
+		sort.Sort(byKey{arr, keys})
+		return arr
+	}
+	return v
+}
+
+// / byKey sorts values by a precomputed sort key for each one.
+type byKey struct {
+	values []interface{}
+	keys   []string
+}
+
+func (b byKey) Len() int           { return len(b.values) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
+
+// /**
+// * @brief Applies fn to every leaf of one JSON type, e.g. to trim all strings.
+// *
+// * @details kind is one of "string", "number", "boolean" or "null", as reported by valueKind. Leaves of
+// * other types, and all object keys, are left alone. Whatever fn returns replaces the leaf, so it may
+// * change the type (returning nil turns the leaf into null). The input is not modified; a transformed
+// * copy is returned.
+// *
+// * @param root The JSON value to transform.
+// * @param kind The JSON type of the leaves to transform.
+// * @param fn The function applied to each matching leaf.
+// * @return A copy of root with the matching leaves replaced.
+// */
+func MapLeaves(root interface{}, kind string, fn func(interface{}) interface{}) interface{} {
+	switch v := untypedSlice(root).(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			obj[k] = MapLeaves(val, kind, fn)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, val := range v {
+			arr[i] = MapLeaves(val, kind, fn)
+		}
+		return arr
+	}
+	if valueKind(root) == kind {
+		return fn(root)
+	}
+	return root
+}
+
+// /**
+// * @brief Makes a deep copy of a parsed JSON value.
+// *
+// * @details Objects and arrays, including typed slices from ParseOptions.TypedSlices, are copied at
+// * every level; scalars are immutable and shared. Use it before modifying a tree that is shared, such as
+// * one returned by a ParserCache.
+// *
+// * @param v The JSON value to copy.
+// * @return The copy.
+// */
+func Clone(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(vv))
+		for k, val := range vv {
+			obj[k] = Clone(val)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(vv))
+		for i, val := range vv {
+			arr[i] = Clone(val)
+		}
+		return arr
+	case []float64:
+		return append([]float64(nil), vv...)
+	case []int64:
+		return append([]int64(nil), vv...)
+	case []string:
+		return append([]string(nil), vv...)
+	}
+	return v
+}
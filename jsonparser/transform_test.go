@@ -0,0 +1,152 @@
+package jsonparser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSortArrays(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      string
+		wantEqual bool
+	}{
+		{"numbers in different orders", `[3, 1, 2]`, `[2, 3, 1]`, true},
+		{"strings in different orders", `["b", "a", "c"]`, `["c", "b", "a"]`, true},
+		{"mixed types", `[null, "x", 1, true, {"a": 1}, []]`, `[[], {"a": 1}, true, 1, "x", null]`, true},
+		{"arrays nested in objects", `{"tags": ["x", "y"], "n": 1}`, `{"n": 1, "tags": ["y", "x"]}`, true},
+		{"objects ordered by their content", `[{"id": 2, "k": "b"}, {"id": 1, "k": "a"}]`, `[{"k": "a", "id": 1}, {"k": "b", "id": 2}]`, true},
+		{"nested arrays sorted before their parent", `[[2, 1], [4, 3]]`, `[[3, 4], [1, 2]]`, true},
+		{"duplicates are kept", `[1, 1, 2]`, `[1, 2, 2]`, false},
+		{"different elements stay different", `[1, 2]`, `[1, 3]`, false},
+		{"different lengths", `[1]`, `[1, 1]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+			before := compactString(a)
+			sa, sb := SortArrays(a), SortArrays(b)
+			if got := Equal(sa, sb); got != tt.wantEqual {
+				t.Errorf("Equal after sorting = %v, want %v\n%s\n%s", got, tt.wantEqual, compactString(sa), compactString(sb))
+			}
+			if tt.wantEqual && compactString(sa) != compactString(sb) {
+				t.Errorf("sorted forms differ: %s and %s", compactString(sa), compactString(sb))
+			}
+			if compactString(a) != before {
+				t.Error("SortArrays modified its input")
+			}
+		})
+	}
+}
+
+func TestSortArraysTypedSlices(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"floats", []float64{3, 1, 2}, []interface{}{float64(1), float64(2), float64(3)}},
+		{"integers", []int64{10, 9}, []interface{}{int64(10), int64(9)}},
+		{"strings", []string{"b", "a"}, []interface{}{"a", "b"}},
+		{"scalar", "x", "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SortArrays(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortArrays = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoalesceBlankStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"spaces become null", `{"a":"  ","b":"x"}`, `{"a":null,"b":"x"}`},
+		{"empty string", `""`, `null`},
+		{"tabs and newlines", `["\t\n", " x "]`, `[null," x "]`},
+		{"nested", `{"a":[{"b":" "}],"c":{"d":""}}`, `{"a":[{"b":null}],"c":{"d":null}}`},
+		{"keys are left alone", `{" ":"v"}`, `{" ":"v"}`},
+		{"other types are left alone", `[0,false,null,{}]`, `[0,false,null,{}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, tt.input)
+			before := compactString(v)
+			if got := compactString(CoalesceBlankStrings(v)); got != tt.want {
+				t.Errorf("CoalesceBlankStrings(%s) = %s, want %s", tt.input, got, tt.want)
+			}
+			if compactString(v) != before {
+				t.Error("CoalesceBlankStrings modified its input")
+			}
+		})
+	}
+}
+
+func TestPreview(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		max   int
+		want  string
+	}{
+		{"long array is cut", `[1,2,3,4,5]`, 2, `[1,2,"... (3 more)"]`},
+		{"short array is unchanged", `[1,2]`, 3, `[1,2]`},
+		{"exactly max elements is unchanged", `[1,2,3]`, 3, `[1,2,3]`},
+		{"nested arrays are cut", `{"a":[[1,2,3],[4]],"b":"x"}`, 1, `{"a":[[1,"... (2 more)"],"... (1 more)"],"b":"x"}`},
+		{"zero keeps only the marker", `[1,2]`, 0, `["... (2 more)"]`},
+		{"negative disables truncation", `[1,2,3]`, -1, `[1,2,3]`},
+		{"scalar", `"s"`, 1, `"s"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, tt.input)
+			before := compactString(v)
+			if got := compactString(Preview(v, tt.max)); got != tt.want {
+				t.Errorf("Preview(%s, %d) = %s, want %s", tt.input, tt.max, got, tt.want)
+			}
+			if compactString(v) != before {
+				t.Error("Preview modified its input")
+			}
+		})
+	}
+}
+
+func TestMapLeaves(t *testing.T) {
+	trim := func(v interface{}) interface{} { return strings.TrimSpace(v.(string)) }
+	tests := []struct {
+		name  string
+		input string
+		kind  string
+		fn    func(interface{}) interface{}
+		want  string
+	}{
+		{"trims string leaves only", `{" k ": " v ", "n": 1, "b": true, "z": null, "a": [" x ", 2]}`, "string", trim,
+			`{" k ":"v","a":["x",2],"b":true,"n":1,"z":null}`},
+		{"numbers", `{"a": 1, "b": "1", "c": [2.5]}`, "number", func(v interface{}) interface{} { return v.(float64) * 2 },
+			`{"a":2,"b":"1","c":[5]}`},
+		{"may change the type", `[true, false, "true"]`, "boolean", func(v interface{}) interface{} { return nil },
+			`[null,null,"true"]`},
+		{"null leaves", `{"a": null, "b": 0}`, "null", func(interface{}) interface{} { return "" },
+			`{"a":"","b":0}`},
+		{"containers are not leaves", `{"a": {}, "b": []}`, "object", func(interface{}) interface{} { return 1 },
+			`{"a":{},"b":[]}`},
+		{"scalar root", `" s "`, "string", trim, `"s"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, tt.input)
+			before := compactString(v)
+			if got := compactString(MapLeaves(v, tt.kind, tt.fn)); got != tt.want {
+				t.Errorf("MapLeaves(%s, %s) = %s, want %s", tt.input, tt.kind, got, tt.want)
+			}
+			if compactString(v) != before {
+				t.Error("MapLeaves modified its input")
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package jsonparser
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// /**
+// * @brief Parses JSON text and stores the result in the value v points to.
+// *
+// * @details Works like encoding/json's Unmarshal for the common cases, on top of this parser: structs
+// * (matching members to exported fields by their `json:"name"` tag or, failing that, by field name
+// * ignoring case, and flattening embedded structs), slices, arrays, maps with string keys, pointers,
+// * interface{} and the scalar kinds. Members with no matching field are ignored, and null leaves
+// * non-pointer fields untouched. A value of the wrong JSON type, such as a string for an int field,
+// * is an error naming the JSON Pointer of the offending value.
+// *
+// * Numbers are parsed with ParseOptions.PreserveIntegers, so an integer field receives the exact value
+// * of any integer that fits int64, such as the ID 9007199254740993 that a float64 would round. An
+// * interface{} destination receives such integers as int64.
+// *
+// * @param data The JSON text.
+// * @param v A non-nil pointer to the value to fill.
+// * @return Any parse or type error.
+// */
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("unmarshal needs a non-nil pointer, got %T", v)
+	}
+	value, err := ParseJSONWithOptions(string(data), ParseOptions{PreserveIntegers: true})
+	if err != nil {
+		return err
+	}
+	return assign(rv.Elem(), value, "")
+}
+
+// /**
+// * @brief Stores a parsed JSON value into a settable Go value, converting it to the Go value's type.
+// *
+// * @param dst The value to set.
+// * @param value The parsed JSON value.
+// * @param pointer The JSON Pointer of value, used in error messages.
+// * @return An error if value doesn't fit dst's type.
+// */
+func assign(dst reflect.Value, value interface{}, pointer string) error {
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		if value == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+		} else {
+			dst.Set(reflect.ValueOf(value))
+		}
+		return nil
+	}
+	if value == nil {
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		return nil
+	}
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(dst.Elem(), value, pointer)
+	}
+
+	mismatch := func() error {
+		return fmt.Errorf("cannot unmarshal %s into %s at %q", valueKind(value), dst.Type(), pointer)
+	}
+	switch dst.Kind() {
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetBool(b)
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := value.(int64); ok {
+			if dst.OverflowInt(n) {
+				return fmt.Errorf("number %d does not fit %s at %q", n, dst.Type(), pointer)
+			}
+			dst.SetInt(n)
+			break
+		}
+		f, ok := value.(float64)
+		if !ok {
+			return mismatch()
+		}
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || dst.OverflowInt(int64(f)) {
+			return fmt.Errorf("number %v does not fit %s at %q", f, dst.Type(), pointer)
+		}
+		dst.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := value.(int64); ok {
+			if n < 0 || dst.OverflowUint(uint64(n)) {
+				return fmt.Errorf("number %d does not fit %s at %q", n, dst.Type(), pointer)
+			}
+			dst.SetUint(uint64(n))
+			break
+		}
+		f, ok := value.(float64)
+		if !ok {
+			return mismatch()
+		}
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || dst.OverflowUint(uint64(f)) {
+			return fmt.Errorf("number %v does not fit %s at %q", f, dst.Type(), pointer)
+		}
+		dst.SetUint(uint64(f))
+	case reflect.Float32, reflect.Float64:
+		if n, ok := value.(int64); ok {
+			value = float64(n)
+		}
+		f, ok := value.(float64)
+		if !ok {
+			return mismatch()
+		}
+		if dst.OverflowFloat(f) {
+			return fmt.Errorf("number %v does not fit %s at %q", f, dst.Type(), pointer)
+		}
+		dst.SetFloat(f)
+	case reflect.Slice:
+		arr, ok := untypedSlice(value).([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(arr), len(arr))
+		for i, elem := range arr {
+			if err := assign(slice.Index(i), elem, pointer+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case reflect.Array:
+		arr, ok := untypedSlice(value).([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		/// Extra elements are dropped and missing ones left zero, as encoding/json does.
+		dst.Set(reflect.Zero(dst.Type()))
+		for i := 0; i < dst.Len() && i < len(arr); i++ {
+			if err := assign(dst.Index(i), arr[i], pointer+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		if dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot unmarshal into %s at %q: map keys must be strings", dst.Type(), pointer)
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), len(obj)))
+		}
+		for key, val := range obj {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(elem, val, pointer+"/"+escapePointerToken(key)); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		fields := structFields(dst.Type(), nil)
+		for key, val := range obj {
+			index, ok := fields[key]
+			if !ok {
+				index, ok = fields[strings.ToLower(key)]
+			}
+			if !ok {
+				continue
+			}
+			if err := assign(dst.FieldByIndex(index), val, pointer+"/"+escapePointerToken(key)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot unmarshal into unsupported type %s at %q", dst.Type(), pointer)
+	}
+	return nil
+}
+
+// /**
+// * @brief Maps JSON member names to the struct fields they fill.
+// *
+// * @details Each exported field is listed under its `json` tag name, or its Go name when untagged, and
+// * also under the lower-cased name for case-insensitive matching. Fields tagged "-" are skipped, and
+// * untagged embedded structs contribute their own fields; an outer field wins over an embedded one.
+// *
+// * @param t The struct type.
+// * @param prefix The field index path of t within the outermost struct.
+// * @return The field index paths by member name.
+// */
+func structFields(t reflect.Type, prefix []int) map[string][]int {
+	fields := make(map[string][]int)
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int(nil), prefix...), i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			field.Index = index
+			embedded = append(embedded, field)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = index
+		if lower := strings.ToLower(name); fields[lower] == nil {
+			fields[lower] = index
+		}
+	}
+	for _, field := range embedded {
+		for name, inner := range structFields(field.Type, field.Index) {
+			if _, taken := fields[name]; !taken {
+				fields[name] = inner
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,46 @@
+package jsonparser
+
+import (
+	"testing"
+)
+
+func TestUnmarshalIntegers(t *testing.T) {
+	type record struct {
+		ID    int64   `json:"id"`
+		Count uint32  `json:"count"`
+		Ratio float64 `json:"ratio"`
+	}
+	tests := []struct {
+		name    string
+		input   string
+		want    record
+		wantErr string
+	}{
+		{"exact large id", `{"id": 9007199254740993}`, record{ID: 9007199254740993}, ""},
+		{"max int64", `{"id": 9223372036854775807}`, record{ID: 9223372036854775807}, ""},
+		{"integer into float", `{"ratio": 3}`, record{Ratio: 3}, ""},
+		{"whole float into int", `{"count": 5.0}`, record{Count: 5}, ""},
+		{"negative into unsigned", `{"count": -1}`, record{}, "does not fit uint32"},
+		{"fraction into int", `{"id": 1.5}`, record{}, "does not fit int64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got record
+			err := Unmarshal([]byte(tt.input), &got)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && got != tt.want {
+				t.Errorf("Unmarshal = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalIntoInterface(t *testing.T) {
+	var got map[string]interface{}
+	if err := Unmarshal([]byte(`{"id": 9007199254740993, "x": 0.5}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["id"] != int64(9007199254740993) || got["x"] != 0.5 {
+		t.Errorf("Unmarshal = %#v", got)
+	}
+}
@@ -0,0 +1,157 @@
+package jsonparser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// / WalkFunc is called by Walk for every value in the tree.
+// / The pointer is the RFC 6901 JSON Pointer of the value ("" for the root).
+// / Returning a non-nil error stops the walk and is returned by Walk.
+type WalkFunc func(pointer string, value interface{}) error
+
+// /**
+// * @brief Visits every value of a parsed JSON tree in depth-first order.
+// *
+// * @details The callback is invoked for containers before their children. Object members are
+// * visited in sorted key order and array elements in index order, so the traversal is deterministic.
+// *
+// * @param root The JSON value to walk.
+// * @param fn The callback invoked for each value.
+// * @return The first error returned by fn, or nil.
+// */
+func Walk(root interface{}, fn WalkFunc) error {
+	return walk("", root, fn)
+}
+
+func walk(pointer string, value interface{}, fn WalkFunc) error {
+	if err := fn(pointer, value); err != nil {
+		return err
+	}
+	switch v := untypedSlice(value).(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := walk(pointer+"/"+escapePointerToken(k), v[k], fn); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			if err := walk(pointer+"/"+strconv.Itoa(i), val, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// /**
+// * @brief Escapes a single JSON Pointer reference token.
+// *
+// * @details Per RFC 6901, '~' is written as "~0" and '/' as "~1".
+// *
+// * @param token The raw object key.
+// * @return The escaped reference token.
+// */
+func escapePointerToken(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+// /**
+// * @brief Names the JSON type of a parsed value.
+// *
+// * @param v The value.
+// * @return One of "object", "array", "string", "number", "boolean", "null", or "" for non-JSON types.
+// */
+func valueKind(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}, []float64, []int64, []string:
+		return "array"
+	case string:
+		return "string"
+	case float64, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return ""
+}
+
+// / ExtractOptions selects what ExtractStringsWithOptions collects besides string values.
+type ExtractOptions struct {
+	IncludeKeys bool ///< also collect object keys, each reported at the pointer of its member
+}
+
+// / ExtractedString is one string found in a tree, with where it was found.
+type ExtractedString struct {
+	Pointer string ///< JSON Pointer of the string value, or of the member whose key this is
+	Text    string ///< the string
+	IsKey   bool   ///< whether Text is an object key rather than a value
+}
+
+// /**
+// * @brief Collects every string value in a parsed tree, e.g. to feed a full-text index.
+// *
+// * @param root The JSON value to search.
+// * @return The string values in Walk order.
+// */
+func ExtractStrings(root interface{}) []string {
+	var texts []string
+	for _, s := range ExtractStringsWithOptions(root, ExtractOptions{}) {
+		texts = append(texts, s.Text)
+	}
+	return texts
+}
+
+// /**
+// * @brief Collects every string value in a parsed tree, and optionally every key, with its path.
+// *
+// * @details Strings are reported in Walk order. A key is reported just before the value of its member.
+// *
+// * @param root The JSON value to search.
+// * @param opts What to collect.
+// * @return The strings found.
+// */
+func ExtractStringsWithOptions(root interface{}, opts ExtractOptions) []ExtractedString {
+	var found []ExtractedString
+	extractStrings("", root, &opts, &found)
+	return found
+}
+
+func extractStrings(pointer string, value interface{}, opts *ExtractOptions, found *[]ExtractedString) {
+	switch v := untypedSlice(value).(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := pointer + "/" + escapePointerToken(k)
+			if opts.IncludeKeys {
+				*found = append(*found, ExtractedString{Pointer: child, Text: k, IsKey: true})
+			}
+			extractStrings(child, v[k], opts, found)
+		}
+	case []interface{}:
+		for i, val := range v {
+			extractStrings(pointer+"/"+strconv.Itoa(i), val, opts, found)
+		}
+	case string:
+		*found = append(*found, ExtractedString{Pointer: pointer, Text: v})
+	}
+}
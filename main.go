@@ -1,543 +1,103 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
+	"time"
 
+	"github.com/itsadijmbt/JsonParser/jsonparser"
 	"github.com/itsadijmbt/JsonParser/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// / TokenType defines the possible types of tokens in JSON.
-type TokenType int
-
 const jsonFile = "data.json"
 
-// ! defined grammer
-const (
-	TokenObjectStart TokenType = iota ///< {
-	TokenObjectEnd                    ///< }
-	TokenArrayStart                   ///< [
-	TokenArrayEnd                     ///< ]
-	TokenColon                        ///< :
-	TokenComma                        ///< ,
-	TokenString                       ///< string literal
-	TokenNumber                       ///< number
-	TokenTrue                         ///< true
-	TokenFalse                        ///< false
-	TokenNull                         ///< null
-	TokenEOF                          ///< end of input
-)
-
-// / Token represents a single token with its type and optional value.
-// / The Value field is a string for TokenString, a float64 for TokenNumber, and nil otherwise.
-type Token struct {
-	Type  TokenType
-	Value interface{}
-}
+// / placeholder is written to an empty input file to show where the JSON goes.
+const placeholder = "// Paste your JSON here and save"
 
 // /**
-// * @brief Tokenizes a JSON string into a slice of tokens.
-// *
-// * @details This function iterates through the input JSON string, identifying and categorizing tokens.
-// * It handles:
-// * - Whitespace characters (' ', '\t', '\n', '\r') by skipping them as they are insignificant outside strings.
-// * - Structural characters ('{', '}', '[', ']', ':', ',') by mapping them to their respective token types.
-// * - String literals (starting with '"') by delegating to parseString.
-// * - Numbers (starting with digits or '-') by delegating to parseNumber.
-// * - Literal 'true' (starting with 't') by checking the full word and creating a TokenTrue token.
-// * - Literal 'false' (starting with 'f') by checking the full word and creating a TokenFalse token.
-// * - Literal 'null' (starting with 'n') by checking the full word and creating a TokenNull token.
-// * - Unexpected characters by returning an error.
-// * The function appends a TokenEOF at the end to signify the end of input.
+// * @brief Reports whether the input holds nothing but whitespace and comments, like a fresh placeholder file.
 // *
-// * @param jsonStr The JSON string to tokenize.
-// * @return A slice of tokens and an error (nil if successful).
+// * @param jsonStr The file content.
+// * @return True if there is no JSON value to parse.
 // */
-func tokenize(jsonStr string) ([]Token, error) {
-	var tokens []Token
-	index := 0
-	/// Skip whitespace characters: space (' '), tab ('\t'), newline ('\n'), carriage return ('\r')
-	for index < len(jsonStr) {
-		char := jsonStr[index]
-		if char == ' ' || char == '\t' || char == '\n' || char == '\r' {
-			index++
-			continue
-		}
-		switch char {
-		case '{':
-			/// Append token for object start.
-			tokens = append(tokens, Token{Type: TokenObjectStart})
-			index++
-		case '}':
-			/// Append token for object end.
-			tokens = append(tokens, Token{Type: TokenObjectEnd})
-			index++
-		case '[':
-			/// Append token for array start.
-			tokens = append(tokens, Token{Type: TokenArrayStart})
-			index++
-		case ']':
-			/// Append token for array end.
-			tokens = append(tokens, Token{Type: TokenArrayEnd})
-			index++
-		case ':':
-			/// Append token for colon.
-			tokens = append(tokens, Token{Type: TokenColon})
-			index++
-		case ',':
-			/// Append token for comma.
-			tokens = append(tokens, Token{Type: TokenComma})
-			index++
-		case '"':
-			/// Parse string literal.
-			str, newIndex, err := parseString(jsonStr, index)
-			if err != nil {
-				return nil, err
-			}
-			tokens = append(tokens, Token{Type: TokenString, Value: str})
-			index = newIndex
-		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '-':
-			/// Parse number.
-			num, newIndex, err := parseNumber(jsonStr, index)
-			if err != nil {
-				return nil, err
-			}
-			tokens = append(tokens, Token{Type: TokenNumber, Value: num})
-			index = newIndex
-		case 't':
-			/// Handle literal 'true'.
-			if index+4 <= len(jsonStr) && jsonStr[index:index+4] == "true" {
-				tokens = append(tokens, Token{Type: TokenTrue})
-				index += 4
-			} else {
-				return nil, fmt.Errorf("invalid token at %d, expected 'true'", index)
-			}
-		case 'f':
-			/// Handle literal 'false'.
-			if index+5 <= len(jsonStr) && jsonStr[index:index+5] == "false" {
-				tokens = append(tokens, Token{Type: TokenFalse})
-				index += 5
-			} else {
-				return nil, fmt.Errorf("invalid token at %d, expected 'false'", index)
-			}
-		case 'n':
-			/// Handle literal 'null'.
-			if index+4 <= len(jsonStr) && jsonStr[index:index+4] == "null" {
-				tokens = append(tokens, Token{Type: TokenNull})
-				index += 4
-			} else {
-				return nil, fmt.Errorf("invalid token at %d, expected 'null'", index)
-			}
-		default:
-			return nil, fmt.Errorf("unexpected character at %d: %c", index, char)
-		}
-	}
-	/// Append end-of-file token.
-	tokens = append(tokens, Token{Type: TokenEOF})
-	return tokens, nil
-}
-
-// /**
-// * @brief Parses a JSON string literal starting at the given index.
-// *
-// * @details This function processes a string literal starting with '"', handling:
-// * - Normal characters by adding them to the result.
-// * - Escape sequences (e.g., '\t', '\n', '\r', '\f', '\b') by interpreting them correctly.
-// * - Unicode escapes ('\uXXXX') by converting the hexadecimal code to a rune.
-// * - The closing quote ('"') to terminate the string.
-// * It returns an error if the string is unterminated or contains invalid escape sequences.
-// *
-// * @param jsonStr The JSON string being parsed.
-// * @param index The current index in the string (should point to the opening quote).
-// * @return The parsed string, the new index after the closing quote, and any error.
-
-func parseString(jsonStr string, index int) (string, int, error) {
-	if jsonStr[index] != '"' {
-		return "", index, fmt.Errorf("expected quote at %d", index)
+func onlyComments(jsonStr string) bool {
+	tokens, err := jsonparser.TokenizeWithOptions(jsonStr, jsonparser.ParseOptions{AllowComments: true})
+	if err != nil {
+		return false
 	}
-	index++ // Skip opening quote
-
-	var sb strings.Builder
-
-	for index < len(jsonStr) {
-		char := jsonStr[index]
-
-		if char == '"' {
-			/// End of string literal.
-			return sb.String(), index + 1, nil
-		}
-		if char == '\\' {
-			index++
-			if index >= len(jsonStr) {
-				return "", index, fmt.Errorf("unterminated string")
-			}
-
-			switch jsonStr[index] {
-			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
-				/// Append the escaped character.
-				sb.WriteByte(jsonStr[index])
-				index++
-			case 'u':
-				/// Handle Unicode escape sequence '\uXXXX'.
-				if index+4 >= len(jsonStr) {
-					return "", index, fmt.Errorf("invalid unicode escape at %d", index)
-				}
-				hex := jsonStr[index+1 : index+5]
-				r, err := strconv.ParseUint(hex, 16, 32)
-				if err != nil {
-					return "", index, err
-				}
-				sb.WriteRune(rune(r))
-				index += 5
-			default:
-				return "", index, fmt.Errorf("invalid escape character at %d", index)
-			}
-		} else {
-			/// Append regular character.
-			sb.WriteByte(char)
-			index++
+	for _, token := range tokens {
+		if token.Type != jsonparser.TokenComment && token.Type != jsonparser.TokenEOF {
+			return false
 		}
 	}
-	return "", index, fmt.Errorf("unterminated string")
+	return true
 }
 
 // /**
-// * @brief Parses a JSON number starting at the given index.
+// * @brief Reads the file to view, guiding the user instead when it holds no JSON yet.
 // *
-// * @details This function parses numbers, which may include:
-// * - Integers (e.g., "123").
-// * - Floating-point numbers (e.g., "12.34").
-// * - Scientific notation (e.g., "1.23e-4").
-// * It stops parsing when it encounters a character not part of a number and converts the substring to a float64.
+// * @details An empty file gets the placeholder comment written into it. For that file, and for one that
+// * still holds only the placeholder or other comments, the guidance is printed to w and no data is
+// * returned, so the caller exits quietly rather than reporting a parse error.
 // *
-// * @param jsonStr The JSON string being parsed.
-// * @param index The current index in the string (should point to the start of the number).
-// * @return The parsed number as a float64, the new index, and any error.
+// * @param w The writer receiving the guidance.
+// * @param file The JSON file to read.
+// * @return The file's content, nil if there is nothing to show yet, or an open or read error.
 // */
-func parseNumber(jsonStr string, index int) (float64, int, error) {
-	start := index
-	for index < len(jsonStr) {
-		char := jsonStr[index]
-		if (char >= '0' && char <= '9') || char == '.' || char == 'e' || char == 'E' || char == '+' || char == '-' {
-			index++
-		} else {
-			break
-		}
-	}
-	numStr := jsonStr[start:index]
-	num, err := strconv.ParseFloat(numStr, 64)
+func readInput(w io.Writer, file string) ([]byte, error) {
+	f, err := os.OpenFile(file, os.O_RDWR, 0644)
 	if err != nil {
-		return 0, start, err
+		return nil, fmt.Errorf("Cannot open %s: %v", file, err)
 	}
-	return num, index, nil
-}
-
-// / TokenStream manages the sequence of tokens.
-type TokenStream struct {
-	tokens []Token
-	index  int
-}
-
-// /**
-// * @brief Advances to the next token in the stream.
-// *
-// * @details Returns the next token or a TokenEOF if the end of the token list is reached.
-// *
-// * @return The next Token.
-// */
-func (ts *TokenStream) Next() Token {
-	if ts.index < len(ts.tokens) {
-		token := ts.tokens[ts.index]
-		ts.index++
-		return token
-	}
-	return Token{Type: TokenEOF}
-}
-
-// /**
-// * @brief Peeks at the next token without advancing the stream.
-// *
-// * @details Returns the next token or a TokenEOF if the end of the token list is reached.
-// *
-// * @return The next Token without consuming it.
-// */
-func (ts *TokenStream) Peek() Token {
-	if ts.index < len(ts.tokens) {
-		return ts.tokens[ts.index]
-	}
-	return Token{Type: TokenEOF}
-}
+	defer f.Close()
 
-// /**
-// * @brief Parses a slice of tokens into a Go data structure.
-// *
-// * @details Initializes a TokenStream and parses the JSON value, ensuring no extra tokens remain after parsing.
-// *
-// * @param tokens The slice of tokens to parse.
-// * @return The parsed JSON value or an error.
-// */
-func parse(tokens []Token) (interface{}, error) {
-	ts := &TokenStream{tokens: tokens, index: 0}
-	value, err := parseValue(ts)
+	data, err := io.ReadAll(f)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("Read error: %v", err)
 	}
-	if ts.Peek().Type != TokenEOF {
-		return nil, fmt.Errorf("extra tokens after value")
+	if len(data) == 0 {
+		if _, err := f.WriteString(placeholder + "\n"); err != nil {
+			return nil, fmt.Errorf("Cannot write %s: %v", file, err)
+		}
 	}
-	return value, nil
-}
-
-// /**
-// * @brief Parses a single JSON value from the token stream.
-// *
-// * @details Dispatches to specific parsing functions based on the token type:
-// * - TokenObjectStart ('{') -> parseObject
-// * - TokenArrayStart ('[') -> parseArray
-// * - TokenString -> returns the string value
-// * - TokenNumber -> returns the float64 value
-// * - TokenTrue ('true') -> returns true
-// * - TokenFalse ('false') -> returns false
-// * - TokenNull ('null') -> returns nil
-// *
-// * @param ts The TokenStream to read from.
-// * @return The parsed value or an error.
-// */
-func parseValue(ts *TokenStream) (interface{}, error) {
-	token := ts.Next()
-	switch token.Type {
-	case TokenObjectStart:
-		return parseObject(ts)
-	case TokenArrayStart:
-		return parseArray(ts)
-	case TokenString:
-		return token.Value.(string), nil
-	case TokenNumber:
-		return token.Value.(float64), nil
-	case TokenTrue:
-		return true, nil
-	case TokenFalse:
-		return false, nil
-	case TokenNull:
+	if len(data) == 0 || onlyComments(string(data)) {
+		/// Fresh, or still just the placeholder (or other comments): nothing to show yet.
+		fmt.Fprintf(w, "Please add your JSON to %s and run again.\n", file)
 		return nil, nil
-	default:
-		return nil, fmt.Errorf("unexpected token: %v", token)
-	}
-}
-
-// /**
-// * @brief Parses a JSON object from the token stream.
-// *
-// * @details Reads key-value pairs until encountering '}', handling:
-// * - Commas (',') between pairs (except before the first pair).
-// * - Colons (':') between keys and values.
-// * - String keys followed by values of any type.
-// *
-// * @param ts The TokenStream to read from.
-// * @return A map representing the object or an error.
-// */
-func parseObject(ts *TokenStream) (map[string]interface{}, error) {
-	obj := make(map[string]interface{})
-	first := true
-	for {
-		token := ts.Peek()
-		if token.Type == TokenObjectEnd {
-			/// Consume the '}' token and return the object.
-			ts.Next()
-			return obj, nil
-		}
-		if !first {
-			if token.Type != TokenComma {
-				return nil, fmt.Errorf("expected ',' or '}'")
-			}
-			/// Consume the comma.
-			ts.Next()
-			token = ts.Peek()
-		}
-		if token.Type != TokenString {
-			return nil, fmt.Errorf("expected string key")
-		}
-		/// Get the key.
-		key := ts.Next().Value.(string)
-		if ts.Next().Type != TokenColon {
-			return nil, fmt.Errorf("expected ':'")
-		}
-		/// Parse the value.
-		value, err := parseValue(ts)
-		if err != nil {
-			return nil, err
-		}
-		obj[key] = value
-		first = false
 	}
+	return data, nil
 }
 
-// /**
-// * @brief Parses a JSON array from the token stream.
-// *
-// * @details Reads values until encountering ']', handling:
-// * - Commas (',') between values (except before the first value).
-// * - Values of any type (objects, arrays, strings, numbers, true, false, null).
-// *
-// * @param ts The TokenStream to read from.
-// * @return A slice representing the array or an error.
-// */
-func parseArray(ts *TokenStream) ([]interface{}, error) {
-	var arr []interface{}
-	first := true
-	for {
-		token := ts.Peek()
-		if token.Type == TokenArrayEnd {
-			/// Consume the ']' token and return the array.
-			ts.Next()
-			return arr, nil
-		}
-		if !first {
-			if token.Type != TokenComma {
-				return nil, fmt.Errorf("expected ',' or ']'")
-			}
-			/// Consume the comma.
-			ts.Next()
-		}
-		/// Parse the value.
-		value, err := parseValue(ts)
-		if err != nil {
-			return nil, err
-		}
-		arr = append(arr, value)
-		first = false
-	}
-}
+// / inputOptions are the parse options for the file shown in the viewer.
+var inputOptions = jsonparser.ParseOptions{AllowComments: true, PreserveIntegers: true}
 
 // /**
-// * @brief Main entry point to parse a JSON string into a Go data structure.
+// * @brief Parses the file to view, falling back to allowing trailing commas.
 // *
-// * @details Combines tokenization and parsing:
-// * - Calls tokenize to break the JSON string into tokens.
-// * - Calls parse to convert tokens into a Go value.
+// * @details Hand-edited files often have a stray trailing comma. Rather than refuse to open them, such a
+// * file is parsed leniently and a warning for the viewer's banner is returned with the document.
 // *
-// * @param jsonStr The JSON string to parse.
-// * @return The parsed JSON value or an error.
+// * @param file The file's name, for the warning.
+// * @param data The file's content.
+// * @return The document, a warning ("" if the strict parse worked), or the strict parse's error.
 // */
-func ParseJSON(jsonStr string) (interface{}, error) {
-	tokens, err := tokenize(jsonStr)
-	if err != nil {
-		return nil, err
+func parseInput(file string, data []byte) (*jsonparser.Document, string, error) {
+	doc, err := jsonparser.ParseDocument(string(data), inputOptions)
+	if err == nil {
+		return doc, "", nil
 	}
-	return parse(tokens)
-}
-
-// /**
-// * @brief Escapes special characters in a string for JSON output.
-// *
-// * @param s The string to escape.
-// * @return The escaped string enclosed in quotes.
-// */
-func escapeString(s string) string {
-	var sb strings.Builder
-	sb.WriteByte('"')
-	for _, r := range s {
-		switch r {
-		case '"':
-			/// Escape double quotes.
-			sb.WriteString("\\\"")
-		case '\\':
-			/// Escape backslashes.
-			sb.WriteString("\\\\")
-		case '\b':
-			/// Escape backspace.
-			sb.WriteString("\\b")
-		case '\f':
-			/// Escape formfeed.
-			sb.WriteString("\\f")
-		case '\n':
-			/// Escape newline.
-			sb.WriteString("\\n")
-		case '\r':
-			/// Escape carriage return.
-			sb.WriteString("\\r")
-		case '\t':
-			/// Escape tab.
-			sb.WriteString("\\t")
-		default:
-			if r < 32 || r > 126 {
-				/// Escape non-printable characters.
-				sb.WriteString(fmt.Sprintf("\\u%04x", r))
-			} else {
-				sb.WriteRune(r)
-			}
-		}
+	lenient := inputOptions
+	lenient.AllowTrailingCommas = true
+	if lenientDoc, lenientErr := jsonparser.ParseDocument(string(data), lenient); lenientErr == nil {
+		return lenientDoc, fmt.Sprintf("%s has trailing commas; parsed leniently. Please remove them.", file), nil
 	}
-	sb.WriteByte('"')
-	return sb.String()
-}
-
-// /**
-// * @brief Recursively formats the JSON value with indentation.
-// *
-// * @param value The JSON value to format.
-// * @param sb The string builder to append the formatted text.
-// * @param indentLevel The current level of indentation.
-// */
-func prettyPrint(value interface{}, sb *strings.Builder, indentLevel int) {
-	indent := strings.Repeat(" ", indentLevel*2)
-	switch v := value.(type) {
-	case map[string]interface{}:
-		sb.WriteString("{\n")
-		first := true
-		for key, val := range v {
-			if !first {
-				sb.WriteString(",\n")
-			}
-			sb.WriteString(indent + "  \"" + key + "\": ")
-			prettyPrint(val, sb, indentLevel+1)
-			first = false
-		}
-		sb.WriteString("\n" + indent + "}")
-	case []interface{}:
-		sb.WriteString("[\n")
-		first := true
-		for _, val := range v {
-			if !first {
-				sb.WriteString(",\n")
-			}
-			sb.WriteString(indent + "  ")
-			prettyPrint(val, sb, indentLevel+1)
-			first = false
-		}
-		sb.WriteString("\n" + indent + "]")
-	case string:
-		sb.WriteString(escapeString(v))
-	case float64:
-		sb.WriteString(fmt.Sprintf("%v", v))
-	case bool:
-		if v {
-			sb.WriteString("true")
-		} else {
-			sb.WriteString("false")
-		}
-	case nil:
-		sb.WriteString("null")
-	default:
-		sb.WriteString("unknown type")
-	}
-}
-
-// /**
-// * @brief Formats the JSON value into a pretty-printed string.
-// *
-// * @param jsonValue The JSON value to format.
-// * @return A pretty-printed JSON string.
-// */
-func PrettyPrint(jsonValue interface{}) string {
-	var sb strings.Builder
-	prettyPrint(jsonValue, &sb, 0)
-	return sb.String()
+	return nil, "", err
 }
 
 // /**
@@ -545,7 +105,8 @@ func PrettyPrint(jsonValue interface{}) string {
 // *
 // * @details This function traverses the JSON data structure and, for every string that appears to be valid JSON (i.e.,
 // * starting with '{' or '['), it attempts to parse it as JSON and replaces the string with the parsed value.
-// * This is done recursively to account for multiple levels of nested JSON.
+// * This is done recursively to account for multiple levels of nested JSON. Strings that merely look
+// * like JSON are changed too, so the viewer only does this when asked to with -unwrap-nested.
 // *
 // * @param value The JSON value to process.
 // * @return The processed JSON value with nested JSON parsed.
@@ -566,7 +127,7 @@ func processNestedJSON(value interface{}) interface{} {
 		trimmed := strings.TrimSpace(v)
 		if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
 			// Attempt to parse the string as JSON.
-			nested, err := ParseJSON(v)
+			nested, err := jsonparser.ParseJSON(v)
 			if err == nil {
 				return processNestedJSON(nested)
 			}
@@ -577,34 +138,128 @@ func processNestedJSON(value interface{}) interface{} {
 	}
 }
 
+// /**
+// * @brief Checks combinations of command-line flags that can't work together.
+// *
+// * @param watch Whether --watch was given.
+// * @param query The -q expression, empty if none.
+// * @return An error describing the misuse, or nil.
+// */
+func checkFlags(watch bool, query string) error {
+	if watch && query == "" {
+		return fmt.Errorf("--watch only works with -q")
+	}
+	return nil
+}
+
 func main() {
-	/// Example JSON string that includes nested JSON as a string.
-	f, err := os.OpenFile(jsonFile, os.O_RDWR, 0644)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Cannot open %s: %v\n", jsonFile, err)
-		os.Exit(1)
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		os.Exit(runFmt(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	editPath := flag.String("edit", "", "open the editor on the scalar at `path` (e.g. users[0].name)")
+	collapsed := flag.Bool("collapsed", false, "start with every container collapsed, showing only the top-level keys")
+	query := flag.String("q", "", "print the results of the query `expr` (e.g. .users[].name) instead of opening the viewer")
+	pointer := flag.String("p", "", "print the value at the JSON Pointer `path` (e.g. /users/0) instead of opening the viewer")
+	watch := flag.Bool("watch", false, "with -q, re-run the query and print the results whenever the file changes")
+	focus := flag.String("focus", "", "open the viewer with only the branches leading to the results of the query `expr` expanded")
+	unwrapNested := flag.Bool("unwrap-nested", false, "show string values holding JSON objects or arrays as the trees they encode")
+	flag.Parse()
+	if err := checkFlags(*watch, *query); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	/// The file to view defaults to data.json next to the binary.
+	file := jsonFile
+	if flag.NArg() > 0 {
+		file = flag.Arg(0)
 	}
-	defer f.Close()
 
-	info, _ := f.Stat()
-	if info.Size() == 0 {
-		f.WriteString("// Paste your JSON here and save\n")
-		fmt.Printf("Please add your JSON to %s and run again.\n", jsonFile)
+	if *pointer != "" {
+		if err := printPointer(os.Stdout, file, *pointer); err != nil {
+			fmt.Fprintf(os.Stderr, "Pointer error: %v\n", err)
+			os.Exit(1)
+		}
 		return
 	}
-	data, err := io.ReadAll(f)
+
+	if *query != "" {
+		if err := printQuery(os.Stdout, file, *query); err != nil {
+			fmt.Fprintf(os.Stderr, "Query error: %v\n", err)
+			if !*watch {
+				os.Exit(1)
+			}
+		}
+		if *watch {
+			/// Runs until interrupted; errors are reported and the watch goes on.
+			ticker := time.NewTicker(200 * time.Millisecond)
+			defer ticker.Stop()
+			tick := func() (time.Time, bool) { return <-ticker.C, true }
+			watchFile(file, tick, 300*time.Millisecond, func() {
+				fmt.Printf("--- %s changed ---\n", file)
+				if err := printQuery(os.Stdout, file, *query); err != nil {
+					fmt.Fprintf(os.Stderr, "Query error: %v\n", err)
+				}
+			})
+		}
+		return
+	}
+
+	data, err := readInput(os.Stdout, file)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Read error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	result, err := ParseJSON(string(data))
+	if data == nil {
+		return
+	}
+	doc, warning, err := parseInput(file, data)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Parse error: %v\n", err)
+		/// Show lexical errors with their source context; others have no position to point at.
+		var syntaxErr *jsonparser.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			fmt.Fprintf(os.Stderr, "Parse error: %s", syntaxErr.Render(string(data)))
+		} else if _, errs := jsonparser.TokenizeRecoverWithOptions(string(data), inputOptions); len(errs) > 0 {
+			fmt.Fprintf(os.Stderr, "Parse error: %s", errs[0].Render(string(data)))
+		} else {
+			fmt.Fprintf(os.Stderr, "Parse error: %v\n", err)
+		}
 		os.Exit(1)
 	}
-	tree := processNestedJSON(result)
+	tree := doc.Value
+	if *unwrapNested {
+		tree = processNestedJSON(tree)
+	}
+
+	opts := ui.Options{
+		FilePath:  file,
+		FileSize:  int64(len(data)),
+		Format:    jsonparser.PrettyPrint,
+		Collapsed: *collapsed,
+		Comments:  doc.Comments,
+		Source:    data,
+		Warning:   warning,
+	}
+	if *editPath != "" {
+		keys, err := editNodeKeys(tree, *editPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot edit %s: %v\n", *editPath, err)
+			os.Exit(1)
+		}
+		opts.EditPath = keys
+	}
+	if *focus != "" {
+		paths, err := queryNodeKeys(tree, *focus)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Query error: %v\n", err)
+			os.Exit(1)
+		}
+		/// An empty, non-nil Expand still collapses everything when nothing matches.
+		opts.Expand = paths
+	}
 
-	if err := tea.NewProgram(ui.NewModel(tree)).Start(); err != nil {
+	if err := tea.NewProgram(ui.NewModelWithOptions(tree, opts), tea.WithMouseCellMotion()).Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "TUI error: %v\n", err)
 		os.Exit(1)
 	}
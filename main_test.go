@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/itsadijmbt/JsonParser/ui"
+)
+
+func TestReadInput(t *testing.T) {
+	const guidance = "Please add your JSON to "
+	tests := []struct {
+		name      string
+		content   string
+		wantData  bool
+		wantFile  string // file content afterwards
+		wantPrint bool
+	}{
+		{"empty file gets the placeholder", "", false, placeholder + "\n", true},
+		{"only the placeholder", placeholder + "\n", false, placeholder + "\n", true},
+		{"placeholder without a newline", placeholder, false, placeholder, true},
+		{"other comments and whitespace", "\n  /* todo */\n// later\n", false, "\n  /* todo */\n// later\n", true},
+		{"only whitespace", " \n\t\n", false, " \n\t\n", true},
+		{"placeholder above JSON", placeholder + "\n{\"a\": 1}\n", true, placeholder + "\n{\"a\": 1}\n", false},
+		{"plain JSON", `[1, 2]`, true, `[1, 2]`, false},
+		{"invalid JSON is left to the parser", `{"a": `, true, `{"a": `, false},
+		{"unterminated comment is left to the parser", "/* open", true, "/* open", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "data.json", tt.content)
+			var out strings.Builder
+			data, err := readInput(&out, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (data != nil) != tt.wantData {
+				t.Errorf("data = %q, want data: %v", data, tt.wantData)
+			}
+			if tt.wantData && string(data) != tt.content {
+				t.Errorf("data = %q, want %q", data, tt.content)
+			}
+			if printed := strings.HasPrefix(out.String(), guidance+path); printed != tt.wantPrint {
+				t.Errorf("printed %q, want guidance: %v", out.String(), tt.wantPrint)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.wantFile {
+				t.Errorf("file holds %q, want %q", got, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestReadInputMissingFile(t *testing.T) {
+	var out strings.Builder
+	_, err := readInput(&out, filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil || !strings.HasPrefix(err.Error(), "Cannot open") {
+		t.Errorf("got %v, want an open error", err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("printed %q", out.String())
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	const lenient = "data.json has trailing commas; parsed leniently. Please remove them."
+	tests := []struct {
+		name        string
+		content     string
+		want        interface{}
+		wantWarning string
+		wantErr     string
+	}{
+		{"strict JSON", `{"a": [1, 2]}`, map[string]interface{}{"a": []interface{}{int64(1), int64(2)}}, "", ""},
+		{"comments are always allowed", "// c\n[1]", []interface{}{int64(1)}, "", ""},
+		{"trailing comma in an array", `[1, 2,]`, []interface{}{int64(1), int64(2)}, lenient, ""},
+		{"trailing comma in an object", "{\n  \"a\": 1,\n}\n", map[string]interface{}{"a": int64(1)}, lenient, ""},
+		{"other errors stay errors", `{"a": 1,, }`, nil, "", "line 1, col 9"},
+		{"trailing comma with another error", `[1, 2,] x`, nil, "", "line 1, col 9: x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, warning, err := parseInput("data.json", []byte(tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(doc.Value, tt.want) {
+				t.Errorf("value = %#v, want %#v", doc.Value, tt.want)
+			}
+			if warning != tt.wantWarning {
+				t.Errorf("warning = %q, want %q", warning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestTrailingCommaWarningShownInViewer(t *testing.T) {
+	doc, warning, err := parseInput("data.json", []byte(`{"a": 1,}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := ui.NewModelWithOptions(doc.Value, ui.Options{Warning: warning})
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	if view := m.View(); !strings.Contains(view, warning) {
+		t.Errorf("viewer lacks the warning banner:\n%s", view)
+	}
+}
+
+func TestCheckFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		watch   bool
+		query   string
+		wantErr string
+	}{
+		{"no flags", false, "", ""},
+		{"query", false, ".a", ""},
+		{"watch with a query", true, ".a", ""},
+		{"watch alone", true, "", "--watch only works with -q"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFlags(tt.watch, tt.query)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("checkFlags = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
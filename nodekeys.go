@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+// /**
+// * @brief Resolves an --edit path to the node keys the viewer uses to locate it.
+// *
+// * @details The path must name a scalar: objects and arrays can't be edited inline.
+// *
+// * @param root The parsed JSON value.
+// * @param path The dotted/bracket path given on the command line.
+// * @return The viewer node keys (array indices written as "[N]") or an error.
+// */
+func editNodeKeys(root interface{}, path string) ([]string, error) {
+	value, err := jsonparser.GetPath(root, path)
+	if err != nil {
+		return nil, err
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return nil, fmt.Errorf("path does not resolve to a scalar value")
+	}
+	pointer, err := jsonparser.PathPointer(path)
+	if err != nil {
+		return nil, err
+	}
+	return pointerKeys(root, pointer), nil
+}
+
+// /**
+// * @brief Resolves the results of a --focus query to the node keys the viewer uses to locate them.
+// *
+// * @param root The parsed JSON value.
+// * @param expr The query expression given on the command line, e.g. ".users[].name".
+// * @return The viewer node keys of every result that exists in the document, or a query error.
+// */
+func queryNodeKeys(root interface{}, expr string) ([][]string, error) {
+	pointers, err := jsonparser.QueryPaths(root, expr)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([][]string, 0, len(pointers))
+	for _, pointer := range pointers {
+		paths = append(paths, pointerKeys(root, pointer))
+	}
+	return paths, nil
+}
+
+// /**
+// * @brief Converts a JSON Pointer that resolves in root to the viewer's node keys.
+// *
+// * @details Whether a reference token is an array index or an object key depends on the container it
+// * steps into, so the pointer is followed through the document.
+// *
+// * @param root The parsed JSON value.
+// * @param pointer A JSON Pointer known to resolve, e.g. "/users/0/name".
+// * @return The node keys, with array indices written as "[N]".
+// */
+func pointerKeys(root interface{}, pointer string) []string {
+	if pointer == "" {
+		return []string{}
+	}
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	refs := strings.Split(pointer[1:], "/")
+	keys := make([]string, len(refs))
+	value := root
+	for i, ref := range refs {
+		ref = unescape.Replace(ref)
+		switch v := value.(type) {
+		case []interface{}:
+			index, _ := strconv.Atoi(ref)
+			keys[i], value = fmt.Sprintf("[%d]", index), v[index]
+		case map[string]interface{}:
+			keys[i], value = ref, v[ref]
+		}
+	}
+	return keys
+}
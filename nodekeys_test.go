@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+func TestQueryNodeKeys(t *testing.T) {
+	const doc = `{"users": [{"name": "ann", "tags": ["x"]}, {"name": "bob"}], "a/b": {"~": [[1]]}}`
+	tests := []struct {
+		name    string
+		expr    string
+		want    [][]string
+		wantErr string
+	}{
+		{"root", ".", [][]string{{}}, ""},
+		{"leaf in every element", ".users[].name", [][]string{{"users", "[0]", "name"}, {"users", "[1]", "name"}}, ""},
+		{"negative index", ".users[-1]", [][]string{{"users", "[1]"}}, ""},
+		{"nested arrays", `.["a/b"]["~"][0][0]`, [][]string{{"a/b", "~", "[0]", "[0]"}}, ""},
+		{"only results that exist", ".users[].tags", [][]string{{"users", "[0]", "tags"}}, ""},
+		{"no results", ".missing", [][]string{}, ""},
+		{"bad query", "users", nil, "must start with '.'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := jsonparser.ParseJSONWithOptions(doc, inputOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := queryNodeKeys(tree, tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queryNodeKeys(%s) = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package ui
+
+import "strings"
+
+// breadcrumb describes where the cursor is, e.g.
+// root > users > [3] > address > city. Paths wider than the viewport keep
+// their end, since the innermost keys say the most. The result is cached
+// until the cursor moves to another node.
+func (m *model) breadcrumb() string {
+	n := m.selected()
+	if n == nil {
+		return ""
+	}
+	if n != m.crumbNode {
+		chain := ancestry(m.root, n)
+		keys := make([]string, len(chain))
+		for i, c := range chain {
+			keys[i] = c.Key
+		}
+		m.crumbNode, m.crumb = n, strings.Join(keys, " > ")
+	}
+	crumb := []rune(m.crumb)
+	if width := m.viewport.Width - 2; width > 1 && len(crumb) > width {
+		crumb = append([]rune{'…'}, crumb[len(crumb)-width+1:]...)
+	}
+	return string(crumb)
+}
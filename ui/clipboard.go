@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// writeClipboard puts text on the system clipboard. It is a variable so
+// tests can capture what would have been copied.
+var writeClipboard = clipboard.WriteAll
+
+// ancestry returns the nodes from root down to target, or nil if target
+// isn't in the tree.
+func ancestry(root, target *Node) []*Node {
+	if root == target {
+		return []*Node{root}
+	}
+	for _, c := range root.Children {
+		if chain := ancestry(c, target); chain != nil {
+			return append([]*Node{root}, chain...)
+		}
+	}
+	return nil
+}
+
+// jsonPointer formats the RFC 6901 pointer of the last node in chain.
+func jsonPointer(chain []*Node) string {
+	var sb strings.Builder
+	for i := 1; i < len(chain); i++ {
+		sb.WriteByte('/')
+		key := chain[i].Key
+		if chain[i-1].Kind == KindArray {
+			sb.WriteString(strings.Trim(key, "[]"))
+			continue
+		}
+		key = strings.ReplaceAll(key, "~", "~0")
+		sb.WriteString(strings.ReplaceAll(key, "/", "~1"))
+	}
+	return sb.String()
+}
+
+// dottedPath formats the users[0].name style path of the last node in chain.
+func dottedPath(chain []*Node) string {
+	keys := make([]string, 0, len(chain))
+	for _, n := range chain[1:] {
+		keys = append(keys, n.Key)
+	}
+	return pathLabel(keys)
+}
+
+// copyPath copies the selected node's JSON Pointer, or its dotted path when
+// dotted is set, to the clipboard.
+func (m *model) copyPath(dotted bool) {
+	chain := ancestry(m.root, m.selected())
+	if chain == nil {
+		return
+	}
+	text := jsonPointer(chain)
+	if dotted {
+		text = dottedPath(chain)
+	}
+	if err := writeClipboard(text); err != nil {
+		m.message = "copy failed: " + err.Error()
+		return
+	}
+	m.message = "path copied: " + text
+}
+
+// copyValue copies the selected node's value to the clipboard: a scalar as
+// its plain text, an object or array formatted like a saved file.
+func (m *model) copyValue() {
+	n := m.selected()
+	if n == nil {
+		return
+	}
+	var text string
+	switch n.Kind {
+	case KindObject, KindArray:
+		text = m.format(nodeValue(n))
+	case KindNull:
+		text = "null"
+	default:
+		text = formatValue(n.Value)
+	}
+	if err := writeClipboard(text); err != nil {
+		m.message = "copy failed: " + err.Error()
+		return
+	}
+	m.message = "copied!"
+}
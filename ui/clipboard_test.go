@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+// captureClipboard replaces the clipboard writer for the rest of the test and
+// returns a pointer to the last text written.
+func captureClipboard(t *testing.T) *string {
+	t.Helper()
+	var copied string
+	saved := writeClipboard
+	writeClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	t.Cleanup(func() { writeClipboard = saved })
+	return &copied
+}
+
+func TestCopyPath(t *testing.T) {
+	tree := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Ann"},
+			map[string]interface{}{"a/b": map[string]interface{}{"~x": 1.0}},
+		},
+	}
+	tests := []struct {
+		name    string
+		path    []string
+		dotted  bool
+		want    string
+		message string
+	}{
+		{"pointer", []string{"users", "[0]", "name"}, false, "/users/0/name", "path copied: /users/0/name"},
+		{"dotted", []string{"users", "[0]", "name"}, true, "users[0].name", "path copied: users[0].name"},
+		{"escaped pointer", []string{"users", "[1]", "a/b", "~x"}, false, "/users/1/a~1b/~0x", "path copied: /users/1/a~1b/~0x"},
+		{"root", nil, false, "", "path copied: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := captureClipboard(t)
+			m := newTestModel(t, tree, Options{})
+			selectPath(t, m, tt.path...)
+			m.copyPath(tt.dotted)
+			if *copied != tt.want {
+				t.Errorf("copied %q, want %q", *copied, tt.want)
+			}
+			if m.message != tt.message {
+				t.Errorf("message %q, want %q", m.message, tt.message)
+			}
+		})
+	}
+}
+
+func TestCopyValue(t *testing.T) {
+	tree := map[string]interface{}{
+		"name": "Ann",
+		"age":  1500000000.0,
+		"tags": []interface{}{"a"},
+		"none": nil,
+	}
+	format := func(v interface{}) string { return "formatted" }
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"name", "Ann"},
+		{"age", "1500000000"},
+		{"none", "null"},
+		{"tags", "formatted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			copied := captureClipboard(t)
+			m := newTestModel(t, tree, Options{Format: format})
+			selectPath(t, m, tt.path)
+			m.copyValue()
+			if *copied != tt.want || m.message != "copied!" {
+				t.Errorf("copied %q with message %q, want %q with \"copied!\"", *copied, m.message, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyFailure(t *testing.T) {
+	saved := writeClipboard
+	writeClipboard = func(string) error { return errors.New("no clipboard") }
+	defer func() { writeClipboard = saved }()
+
+	m := newTestModel(t, map[string]interface{}{"a": 1.0}, Options{})
+	selectPath(t, m, "a")
+	m.copyValue()
+	if m.message != "copy failed: no clipboard" {
+		t.Errorf("message %q", m.message)
+	}
+}
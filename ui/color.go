@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Colors for the parts of a tree line; connectors, previews and comments
+// keep the line's base color.
+var (
+	keyColor    = lipgloss.Color("#F8F8F2")
+	stringColor = lipgloss.Color("#50FA7B")
+	numberColor = lipgloss.Color("#FFB86C")
+	boolColor   = lipgloss.Color("#FF79C6")
+	nullColor   = lipgloss.Color("#6272A4")
+)
+
+// kindColor is the color leaf values of kind k are shown in.
+func kindColor(k NodeKind) lipgloss.Color {
+	switch k {
+	case KindString:
+		return stringColor
+	case KindNumber:
+		return numberColor
+	case KindBool:
+		return boolColor
+	case KindNull:
+		return nullColor
+	}
+	return keyColor
+}
+
+// renderLine styles line i: connectors in the base style, the key in white
+// and a leaf value in the color of its type. Search matches keep one color
+// across the whole line so they stand out.
+func (m *model) renderLine(i int, base lipgloss.Style) string {
+	line := m.lines[i]
+	connector := strings.Repeat("─", m.indent)
+	connectors := func(s string) string {
+		return strings.ReplaceAll(s, strings.Repeat("─", 3), connector)
+	}
+	if m.isMatch(i) || i >= len(m.layouts) || i >= len(m.rows) {
+		return base.Render(connectors(line))
+	}
+	layout, n := m.layouts[i], m.rows[i]
+	keyEnd := layout.end
+	if layout.value >= 0 {
+		keyEnd = layout.value
+	}
+	keyStyle := base.Foreground(keyColor)
+	if n.Kind == KindNull {
+		// null leaves show only their key, so the key carries the color
+		keyStyle = base.Foreground(nullColor)
+	}
+	var sb strings.Builder
+	sb.WriteString(base.Render(connectors(line[:layout.key])))
+	sb.WriteString(keyStyle.Render(line[layout.key:keyEnd]))
+	if layout.value >= 0 {
+		sb.WriteString(base.Foreground(kindColor(n.Kind)).Render(line[layout.value:layout.end]))
+	}
+	if layout.end < len(line) {
+		sb.WriteString(base.Render(line[layout.end:]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RenderDOT renders v as a Graphviz DOT digraph. Every node of the tree gets
+// a unique id (n0, n1, ...) and a label with its key; leaves also show their
+// value. There is one edge per parent-child relationship.
+func RenderDOT(v interface{}) string {
+	var sb strings.Builder
+	sb.WriteString("digraph json {\n")
+	sb.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	id := 0
+	writeDOTNode(&sb, BuildNode("root", v), &id)
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// writeDOTNode emits n and its subtree, returning the id given to n.
+func writeDOTNode(sb *strings.Builder, n *Node, next *int) string {
+	id := fmt.Sprintf("n%d", *next)
+	*next++
+
+	label := n.Key
+	switch n.Kind {
+	case KindObject:
+		label += " {}"
+	case KindArray:
+		label += " []"
+	case KindString:
+		label += ": " + strconv.Quote(n.Value.(string))
+	case KindNull:
+		label += ": null"
+	default:
+		label += ": " + formatValue(n.Value)
+	}
+	fmt.Fprintf(sb, "  %s [label=%s];\n", id, dotQuote(label))
+
+	for _, c := range sortedChildren(n) {
+		child := writeDOTNode(sb, c, next)
+		fmt.Fprintf(sb, "  %s -> %s;\n", id, child)
+	}
+	return id
+}
+
+// dotQuote returns s as a DOT double-quoted string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRenderDOT(t *testing.T) {
+	got := RenderDOT(map[string]interface{}{
+		"list":  []interface{}{float64(1), nil},
+		`a"b`:   `x\y`,
+		"empty": map[string]interface{}{},
+	})
+	want := `digraph json {
+  node [shape=box, fontname="monospace"];
+  n0 [label="root {}"];
+  n1 [label="a\"b: \"x\\\\y\""];
+  n0 -> n1;
+  n2 [label="empty {}"];
+  n0 -> n2;
+  n3 [label="list []"];
+  n4 [label="[0]: 1"];
+  n3 -> n4;
+  n5 [label="[1]: null"];
+  n3 -> n5;
+  n0 -> n3;
+}
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+var (
+	dotNode = regexp.MustCompile(`^  (n\d+) \[label=("(?:[^"\\]|\\.)*")\];$`)
+	dotEdge = regexp.MustCompile(`^  (n\d+) -> (n\d+);$`)
+)
+
+func TestRenderDOTEdges(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"scalar", "x"},
+		{"flat object", map[string]interface{}{"a": float64(1), "b": "two", "c": true}},
+		{"nested", map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": []interface{}{nil, "q\"uote\nline"}}}}},
+		{"typed slice", []float64{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := RenderDOT(tt.v)
+			if !strings.HasPrefix(out, "digraph json {\n") || !strings.HasSuffix(out, "}\n") {
+				t.Fatalf("not a digraph:\n%s", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "}\n"), "\n")
+			nodes := map[string]bool{}
+			parents := map[string]string{}
+			for _, line := range lines[2 : len(lines)-1] {
+				if m := dotNode.FindStringSubmatch(line); m != nil {
+					nodes[m[1]] = true
+					continue
+				}
+				m := dotEdge.FindStringSubmatch(line)
+				if m == nil {
+					t.Fatalf("unexpected line %q", line)
+				}
+				if !nodes[m[1]] || !nodes[m[2]] {
+					t.Errorf("edge %s -> %s before its nodes", m[1], m[2])
+				}
+				if p, ok := parents[m[2]]; ok {
+					t.Errorf("%s has two parents, %s and %s", m[2], p, m[1])
+				}
+				parents[m[2]] = m[1]
+			}
+			if len(nodes) != countNodes(tt.v) {
+				t.Errorf("%d nodes, want %d", len(nodes), countNodes(tt.v))
+			}
+			if len(parents) != len(nodes)-1 {
+				t.Errorf("%d edges for %d nodes", len(parents), len(nodes))
+			}
+			if _, ok := parents["n0"]; ok {
+				t.Error("the root has a parent")
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+// findNode follows path, a list of node keys below root such as
+// ["users", "[0]", "name"], and returns the node it names or nil.
+func findNode(root *Node, path []string) *Node {
+	n := root
+	for _, key := range path {
+		var next *Node
+		for _, c := range n.Children {
+			if c.Key == key {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+// expandPath expands every collapsed node along path so its target is shown.
+func expandPath(root *Node, path []string) {
+	for i := range path {
+		if n := findNode(root, path[:i]); n != nil {
+			n.Collapsed = false
+		}
+	}
+}
+
+// pathLabel joins node keys into a readable path like users[0].name.
+func pathLabel(path []string) string {
+	var sb strings.Builder
+	for i, key := range path {
+		if i > 0 && !strings.HasPrefix(key, "[") {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(key)
+	}
+	return sb.String()
+}
+
+// nodeValue rebuilds the plain JSON value a node tree represents.
+func nodeValue(n *Node) interface{} {
+	switch n.Kind {
+	case KindObject:
+		obj := make(map[string]interface{}, len(n.Children))
+		for _, c := range n.Children {
+			obj[c.Key] = nodeValue(c)
+		}
+		return obj
+	case KindArray:
+		arr := make([]interface{}, 0, len(n.Children))
+		for _, c := range n.Children {
+			arr = append(arr, nodeValue(c))
+		}
+		return arr
+	}
+	return n.Value
+}
+
+// editText is the initial editor content for a scalar node.
+func editText(n *Node) string {
+	switch v := n.Value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		return jsonparser.FormatNumber(v)
+	}
+	return fmt.Sprintf("%v", n.Value)
+}
+
+// parseEdit converts editor input into a value of the same kind as the node
+// being edited. Null nodes accept any literal and fall back to a string.
+func parseEdit(kind NodeKind, input string) (interface{}, NodeKind, error) {
+	switch kind {
+	case KindString:
+		return input, KindString, nil
+	case KindNumber:
+		// strconv also takes NaN, Inf, +1 and 0x1p-2, none of which can be
+		// saved as JSON, so the text has to pass the JSON number grammar first
+		text := strings.TrimSpace(input)
+		if err := jsonparser.ValidateNumber(text); err != nil {
+			return nil, kind, fmt.Errorf("%q is not a JSON number: %v", input, err)
+		}
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			// integers stay exact, as the parser's PreserveIntegers keeps them
+			return n, KindNumber, nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, kind, fmt.Errorf("%q is out of range", input)
+		}
+		return f, KindNumber, nil
+	case KindBool:
+		switch strings.TrimSpace(input) {
+		case "true":
+			return true, KindBool, nil
+		case "false":
+			return false, KindBool, nil
+		}
+		return nil, kind, fmt.Errorf("%q is not true or false", input)
+	}
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "null" {
+		return nil, KindNull, nil
+	}
+	if v, _, err := parseEdit(KindBool, trimmed); err == nil {
+		return v, KindBool, nil
+	}
+	if v, _, err := parseEdit(KindNumber, trimmed); err == nil {
+		return v, KindNumber, nil
+	}
+	return input, KindString, nil
+}
+
+// defaultFormat serializes values when Options.Format isn't set.
+func defaultFormat(v interface{}) string {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(out)
+}
+
+// save writes value into the file the tree was loaded from, as the new value
+// of node n. Only that value's text in Options.Source is replaced, so
+// comments, key order and layout are kept. Nodes that aren't in the source,
+// such as those inside strings unwrapped by -unwrap-nested, can't be saved.
+func (m *model) save(n *Node, value interface{}) error {
+	if m.opts.FilePath == "" || m.opts.Source == nil {
+		return fmt.Errorf("no file to save to")
+	}
+	chain := ancestry(m.root, n)
+	edit := jsonparser.Edit{Pointer: jsonPointer(chain), Value: value}
+	out, err := jsonparser.ApplyEdits(string(m.opts.Source), []jsonparser.Edit{edit})
+	if err != nil {
+		return fmt.Errorf("%s is not in the file as shown: %v", dottedPath(chain), err)
+	}
+	if err := os.WriteFile(m.opts.FilePath, []byte(out), 0644); err != nil {
+		return err
+	}
+	m.opts.Source = []byte(out)
+	return nil
+}
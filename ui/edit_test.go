@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+func TestSaveSplicesEdit(t *testing.T) {
+	const src = `{
+  // the service name
+  "zeta": "svc",
+  "alpha": {"port": 8080, "hosts": ["a", "b"]},   /* inline */
+  "id": 9007199254740993,
+  "dup": 1, "dup": 2
+}
+`
+	tests := []struct {
+		name  string
+		path  []string
+		input string
+		want  string
+	}{
+		{"string", []string{"zeta"}, "api", strings.Replace(src, `"svc"`, `"api"`, 1)},
+		{"number in a nested object", []string{"alpha", "port"}, "9090", strings.Replace(src, "8080", "9090", 1)},
+		{"array element", []string{"alpha", "hosts", "[1]"}, "c", strings.Replace(src, `"b"]`, `"c"]`, 1)},
+		{"large integer stays exact", []string{"id"}, "9007199254740995", strings.Replace(src, "9007199254740993", "9007199254740995", 1)},
+		{"duplicate key edits the last member", []string{"dup"}, "3", strings.Replace(src, `"dup": 2`, `"dup": 3`, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "doc.json")
+			if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			doc, err := jsonparser.ParseDocument(src, jsonparser.ParseOptions{AllowComments: true, PreserveIntegers: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := newTestModel(t, doc.Value, Options{FilePath: path, Source: []byte(src), Comments: doc.Comments, EditPath: tt.path})
+			if m.editing == nil {
+				t.Fatalf("editor not open: %s", m.message)
+			}
+			m.input.SetValue(tt.input)
+			m.commitEdit()
+			if !strings.HasPrefix(m.message, "saved") {
+				t.Fatalf("message %q", m.message)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("file holds\n%s\nwant\n%s", data, tt.want)
+			}
+			if string(m.opts.Source) != tt.want {
+				t.Error("Options.Source was not updated to the saved text")
+			}
+		})
+	}
+}
+
+func TestSaveRefusesUnwrappedValue(t *testing.T) {
+	const src = `{"config": "{\"debug\": false}"}`
+	path := filepath.Join(t.TempDir(), "doc.json")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the tree as -unwrap-nested shows it, with the string decoded
+	tree := map[string]interface{}{"config": map[string]interface{}{"debug": false}}
+	m := newTestModel(t, tree, Options{FilePath: path, Source: []byte(src), EditPath: []string{"config", "debug"}})
+	m.input.SetValue("true")
+	m.commitEdit()
+	if !strings.HasPrefix(m.message, "save failed: config.debug is not in the file") {
+		t.Errorf("message %q", m.message)
+	}
+	if data, _ := os.ReadFile(path); string(data) != src {
+		t.Errorf("file changed to %s", data)
+	}
+	if n := findNode(m.root, []string{"config", "debug"}); n.Value != false {
+		t.Errorf("tree shows %v after a failed save", n.Value)
+	}
+}
+
+func TestParseEdit(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     NodeKind
+		input    string
+		want     interface{}
+		wantKind NodeKind
+		wantErr  string
+	}{
+		{"integer stays exact", KindNumber, "9007199254740993", int64(9007199254740993), KindNumber, ""},
+		{"fraction", KindNumber, " 1.5e3 ", 1500.0, KindNumber, ""},
+		{"negative fraction", KindNumber, "-0.25", -0.25, KindNumber, ""},
+		{"NaN", KindNumber, "NaN", nil, KindNumber, `"NaN" is not a JSON number`},
+		{"Inf", KindNumber, "Inf", nil, KindNumber, `"Inf" is not a JSON number`},
+		{"infinity", KindNumber, "-infinity", nil, KindNumber, `"-infinity" is not a JSON number`},
+		{"hex float", KindNumber, "0x1p-2", nil, KindNumber, `"0x1p-2" is not a JSON number`},
+		{"plus sign", KindNumber, "+1", nil, KindNumber, `"+1" is not a JSON number`},
+		{"leading zero", KindNumber, "007", nil, KindNumber, `"007" is not a JSON number: leading zero`},
+		{"underscores", KindNumber, "1_000", nil, KindNumber, `"1_000" is not a JSON number`},
+		{"out of range", KindNumber, "1e400", nil, KindNumber, `"1e400" is out of range`},
+		{"string", KindString, " NaN ", " NaN ", KindString, ""},
+		{"bool", KindBool, "false", false, KindBool, ""},
+		{"not a bool", KindBool, "yes", nil, KindBool, `"yes" is not true or false`},
+		{"null takes a number", KindNull, "42", int64(42), KindNumber, ""},
+		{"null takes null", KindNull, "null", nil, KindNull, ""},
+		{"null keeps NaN as a string", KindNull, "NaN", "NaN", KindString, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, kind, err := parseEdit(tt.kind, tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || kind != tt.wantKind {
+				t.Errorf("parseEdit = %#v (kind %v), want %#v (kind %v)", got, kind, tt.want, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestEditRejectsNonJSONNumber(t *testing.T) {
+	const src = `{"n": 1}`
+	path := filepath.Join(t.TempDir(), "doc.json")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(t, map[string]interface{}{"n": float64(1)}, Options{FilePath: path, Source: []byte(src), EditPath: []string{"n"}})
+	m.input.SetValue("NaN")
+	m.commitEdit()
+	if m.editing == nil || m.mode != inputEdit {
+		t.Error("editor closed on invalid input")
+	}
+	if !strings.Contains(m.message, "not a JSON number") {
+		t.Errorf("message %q", m.message)
+	}
+	if data, _ := os.ReadFile(path); string(data) != src {
+		t.Errorf("file changed to %s", data)
+	}
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// startExport prompts for the file to write n's subtree to.
+func (m *model) startExport(n *Node) {
+	m.exporting = n
+	name := strings.Trim(n.Key, "[]")
+	if name == "" {
+		name = "export"
+	}
+	m.prompt(inputExport, name+".json")
+}
+
+// confirmExport asks before replacing an existing file, otherwise writes.
+func (m *model) confirmExport() {
+	m.exportPath = strings.TrimSpace(m.input.Value())
+	if m.exportPath == "" {
+		m.message = "enter a file name"
+		return
+	}
+	if _, err := os.Stat(m.exportPath); err == nil {
+		m.mode = inputOverwrite
+		m.input.Blur()
+		return
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		m.message = "export failed: " + err.Error()
+		return
+	}
+	m.writeExport()
+}
+
+// writeExport serializes the exported subtree to exportPath.
+func (m *model) writeExport() {
+	data := m.format(nodeValue(m.exporting)) + "\n"
+	m.exporting = nil
+	m.closePrompt()
+	if err := os.WriteFile(m.exportPath, []byte(data), 0644); err != nil {
+		m.message = "export failed: " + err.Error()
+		return
+	}
+	m.message = "exported to " + m.exportPath
+}
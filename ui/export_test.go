@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+func TestExportSubtree(t *testing.T) {
+	const src = `{"users": [{"name": "Ann", "tags": ["a", "b"], "address": {"city": "Paris", "zip": null}}], "count": 1}`
+	doc, err := jsonparser.ParseJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := doc.(map[string]interface{})["users"].([]interface{})
+	tests := []struct {
+		name string
+		path []string
+		want interface{}
+	}{
+		{"object", []string{"users", "[0]"}, users[0]},
+		{"nested object", []string{"users", "[0]", "address"}, users[0].(map[string]interface{})["address"]},
+		{"array", []string{"users"}, users},
+		{"scalar", []string{"count"}, float64(1)},
+		{"whole document", nil, doc},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, doc, Options{})
+			path := filepath.Join(t.TempDir(), "out.json")
+			m.startExport(selectPath(t, m, tt.path...))
+			m.input.SetValue(path)
+			m.confirmExport()
+			if m.message != "exported to "+path {
+				t.Fatalf("message %q", m.message)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := jsonparser.ParseJSON(string(data))
+			if err != nil {
+				t.Fatalf("exported file does not parse: %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("exported %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportAsksBeforeOverwriting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(t, map[string]interface{}{"a": float64(1)}, Options{})
+	m.startExport(selectPath(t, m, "a"))
+	m.input.SetValue(path)
+	m.confirmExport()
+	if m.mode != inputOverwrite {
+		t.Fatalf("mode %v, want the overwrite prompt", m.mode)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old\n" {
+		t.Fatalf("file replaced before confirming: %q", data)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if data, _ := os.ReadFile(path); string(data) != "1\n" {
+		t.Errorf("file holds %q after confirming", data)
+	}
+}
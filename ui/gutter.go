@@ -0,0 +1,22 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gutterStyle dims line numbers so they read as margin, not content.
+var gutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+
+// gutter is the line number shown left of line i when line numbers are on,
+// right-aligned to the width of the last line's number. It is kept apart from
+// renderLine so the connector rewriting there never sees it.
+func (m *model) gutter(i int) string {
+	if !m.numbered {
+		return ""
+	}
+	width := len(strconv.Itoa(len(m.lines)))
+	return gutterStyle.Render(fmt.Sprintf("%*d ", width, i+1))
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Line numbers are only useful for pointing someone at a node if they name
+// the same node every time the document is opened, whatever order Go's map
+// iteration happens to produce.
+func TestGutterNumbersAreStable(t *testing.T) {
+	tree := map[string]interface{}{
+		"zeta":  float64(1),
+		"alpha": "a",
+		"mid":   map[string]interface{}{"y": true, "b": nil, "x": "x"},
+		"beta":  []interface{}{float64(1), float64(2)},
+		"gamma": "g",
+		"delta": "d",
+	}
+	numbered := func() []string {
+		m := newTestModel(t, tree, Options{})
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("#")})
+		if !m.numbered {
+			t.Fatal("# did not turn line numbers on")
+		}
+		out := make([]string, len(m.lines))
+		for i, line := range m.lines {
+			out[i] = m.gutter(i) + line
+		}
+		return out
+	}
+	want := []string{
+		" 1 └─── root",
+		" 2      ├─── alpha: a",
+		" 3      ├─── beta",
+		" 4      │    ├─── [0]: 1",
+		" 5      │    └─── [1]: 2",
+		" 6      ├─── delta: d",
+		" 7      ├─── gamma: g",
+		" 8      ├─── mid",
+		" 9      │    ├─── b",
+		"10      │    ├─── x: x",
+		"11      │    └─── y: true",
+		"12      └─── zeta: 1",
+	}
+	for i := 0; i < 20; i++ {
+		if got := numbered(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("build %d numbered\n%q\nwant\n%q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hexDump renders data xxd-style: an offset, sixteen bytes in hex grouped in
+// pairs, and the same bytes as ASCII with non-printable ones shown as dots.
+func hexDump(data []byte) []string {
+	var lines []string
+	for off := 0; off < len(data); off += 16 {
+		chunk := data[off:min(off+16, len(data))]
+		var hex, ascii strings.Builder
+		for i := 0; i < 16; i++ {
+			if i < len(chunk) {
+				fmt.Fprintf(&hex, "%02x", chunk[i])
+			} else {
+				hex.WriteString("  ")
+			}
+			if i%2 == 1 {
+				hex.WriteByte(' ')
+			}
+		}
+		for _, b := range chunk {
+			if b < 0x20 || b > 0x7e {
+				b = '.'
+			}
+			ascii.WriteByte(b)
+		}
+		lines = append(lines, fmt.Sprintf("%08x: %s %s", off, hex.String(), ascii.String()))
+	}
+	return lines
+}
+
+// toggleHex switches between the tree and a hex dump of the source bytes.
+func (m *model) toggleHex() {
+	if m.opts.Source == nil {
+		m.message = "no source bytes to show"
+		return
+	}
+	m.hex = !m.hex
+	if m.hex && m.hexLines == nil {
+		m.hexLines = hexDump(m.opts.Source)
+	}
+	m.viewport.SetYOffset(0)
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHexDump(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"partial line", `{"a": 1}`, []string{
+			`00000000: 7b22 6122 3a20 317d                      {"a": 1}`,
+		}},
+		{"odd length", "abc", []string{
+			"00000000: 6162 63                                  abc",
+		}},
+		// the same as `xxd` prints for these bytes
+		{"several lines with non-printables", "{\"k\": \"caf\xc3\xa9\\t\"}\n\x00\x7f~ 0123456789abcdef", []string{
+			`00000000: 7b22 6b22 3a20 2263 6166 c3a9 5c74 227d  {"k": "caf..\t"}`,
+			"00000010: 0a00 7f7e 2030 3132 3334 3536 3738 3961  ...~ 0123456789a",
+			"00000020: 6263 6465 66                             bcdef",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hexDump([]byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleHex(t *testing.T) {
+	m := newTestModel(t, []interface{}{float64(1)}, Options{})
+	m.toggleHex()
+	if m.hex || m.message != "no source bytes to show" {
+		t.Errorf("hex view without source: hex %v, message %q", m.hex, m.message)
+	}
+
+	m = newTestModel(t, []interface{}{float64(1)}, Options{Source: []byte("[1]\n")})
+	m.toggleHex()
+	if want := []string{"00000000: 5b31 5d0a                                [1]."}; !m.hex || !reflect.DeepEqual(m.hexLines, want) {
+		t.Errorf("hex %v, lines %q, want %q", m.hex, m.hexLines, want)
+	}
+	m.toggleHex()
+	if m.hex {
+		t.Error("second toggle left the hex view on")
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// kindClass is the CSS class RenderHTML gives each kind of node.
+var kindClass = map[NodeKind]string{
+	KindNull:   "json-null",
+	KindString: "json-string",
+	KindNumber: "json-number",
+	KindBool:   "json-boolean",
+	KindObject: "json-object",
+	KindArray:  "json-array",
+}
+
+// RenderHTML renders v as a static, collapsible HTML tree: nested <ul> lists
+// where objects and arrays are <details> elements (open by default) and
+// every <li> carries a json-object, json-array, json-string, json-number,
+// json-boolean or json-null class for styling. No JavaScript is needed.
+// Object members are listed in sorted key order.
+func RenderHTML(v interface{}) string {
+	var sb strings.Builder
+	sb.WriteString("<ul class=\"json-tree\">\n")
+	writeHTMLNode(&sb, BuildNode("root", v), 1)
+	sb.WriteString("</ul>\n")
+	return sb.String()
+}
+
+func writeHTMLNode(sb *strings.Builder, n *Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	key := `<span class="json-key">` + html.EscapeString(n.Key) + `</span>`
+	fmt.Fprintf(sb, "%s<li class=\"%s\">", indent, kindClass[n.Kind])
+
+	switch n.Kind {
+	case KindObject, KindArray:
+		open, close := "{", "}"
+		if n.Kind == KindArray {
+			open, close = "[", "]"
+		}
+		fmt.Fprintf(sb, "<details open><summary>%s %s%d%s</summary>\n", key, open, len(n.Children), close)
+		if len(n.Children) > 0 {
+			sb.WriteString(indent + "  <ul>\n")
+			for _, c := range sortedChildren(n) {
+				writeHTMLNode(sb, c, depth+2)
+			}
+			sb.WriteString(indent + "  </ul>\n")
+		}
+		sb.WriteString(indent + "</details></li>\n")
+	default:
+		value := "null"
+		switch v := n.Value.(type) {
+		case string:
+			value = fmt.Sprintf("%q", v)
+		case nil:
+		default:
+			value = formatValue(v)
+		}
+		fmt.Fprintf(sb, "%s: <span class=\"json-value\">%s</span></li>\n", key, html.EscapeString(value))
+	}
+}
+
+// sortedChildren returns n's children, ordered by key for objects.
+func sortedChildren(n *Node) []*Node {
+	if n.Kind != KindObject {
+		return n.Children
+	}
+	children := append([]*Node(nil), n.Children...)
+	sort.Slice(children, func(i, j int) bool { return children[i].Key < children[j].Key })
+	return children
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var htmlTag = regexp.MustCompile(`<(/?)([a-z]+)[^>]*>`)
+
+// checkHTMLNesting fails unless every tag in s is closed, in order.
+func checkHTMLNesting(t *testing.T, s string) {
+	t.Helper()
+	var open []string
+	for _, m := range htmlTag.FindAllStringSubmatch(s, -1) {
+		closing, name := m[1] == "/", m[2]
+		if !closing {
+			open = append(open, name)
+			continue
+		}
+		if len(open) == 0 || open[len(open)-1] != name {
+			t.Fatalf("</%s> closes %v\n%s", name, open, s)
+		}
+		open = open[:len(open)-1]
+	}
+	if len(open) > 0 {
+		t.Fatalf("unclosed %v\n%s", open, s)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	got := RenderHTML(map[string]interface{}{
+		"a<b&c": "<x & y>",
+		"list":  []interface{}{float64(1), nil, []interface{}{}},
+	})
+	want := `<ul class="json-tree">
+  <li class="json-object"><details open><summary><span class="json-key">root</span> {2}</summary>
+    <ul>
+      <li class="json-string"><span class="json-key">a&lt;b&amp;c</span>: <span class="json-value">&#34;&lt;x &amp; y&gt;&#34;</span></li>
+      <li class="json-array"><details open><summary><span class="json-key">list</span> [3]</summary>
+        <ul>
+          <li class="json-number"><span class="json-key">[0]</span>: <span class="json-value">1</span></li>
+          <li class="json-null"><span class="json-key">[1]</span>: <span class="json-value">null</span></li>
+          <li class="json-array"><details open><summary><span class="json-key">[2]</span> [0]</summary>
+          </details></li>
+        </ul>
+      </details></li>
+    </ul>
+  </details></li>
+</ul>
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderHTMLNesting(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"scalar", "x"},
+		{"empty object", map[string]interface{}{}},
+		{"empty array", []interface{}{}},
+		{"deep", map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": []interface{}{[]interface{}{true}}}}}},
+		{"markup in keys and values", map[string]interface{}{"</li>": "</ul><ul>", "<details>": []interface{}{"<summary>"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := RenderHTML(tt.v)
+			checkHTMLNesting(t, out)
+			if n := strings.Count(out, "<li "); n != countNodes(tt.v) {
+				t.Errorf("%d <li> elements for %d nodes", n, countNodes(tt.v))
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -12,59 +15,127 @@ import (
 
 type TickMsg time.Time
 
-type Node struct {
-	Key      string
-	Value    interface{}
-	Children []*Node
+// loadThreshold is the node count above which the tree is built in the
+// background behind a progress bar instead of synchronously in NewModel.
+const loadThreshold = 5000
+
+// tickInterval is the pause between two steps of the reveal animation, and
+// revealDuration is how long the whole reveal takes when Options.RevealBatch
+// is left at zero.
+const (
+	tickInterval   = 150 * time.Millisecond
+	revealDuration = 2 * time.Second
+)
+
+// loadProgressMsg reports how far the background tree build has come.
+type loadProgressMsg struct {
+	done  int
+	total int
+}
+
+// loadDoneMsg carries the built tree once the background build finishes.
+type loadDoneMsg struct {
+	root    *Node
+	lines   []string
+	rows    []*Node
+	layouts []lineLayout
 }
 
-func buildNode(key string, v interface{}) *Node {
-	n := &Node{Key: key}
-	switch vv := v.(type) {
-	case map[string]interface{}:
-		for k, val := range vv {
-			n.Children = append(n.Children, buildNode(k, val))
+// loadTree builds and renders the tree in the background, reporting progress
+// on ch roughly once per percent and finishing with a loadDoneMsg. Once stop
+// is closed nothing reads ch any more, so the build stops reporting and the
+// goroutine exits instead of blocking.
+func loadTree(tree interface{}, total int, opts Options, ch chan<- tea.Msg, stop <-chan struct{}) {
+	defer close(ch)
+	send := func(msg tea.Msg) bool {
+		select {
+		case ch <- msg:
+			return true
+		case <-stop:
+			return false
 		}
-	case []interface{}:
-		for i, val := range vv {
-			n.Children = append(n.Children, buildNode(fmt.Sprintf("[%d]", i), val))
+	}
+	// every node is visited once while building and once while rendering
+	total *= 2
+	step := total / 100
+	if step == 0 {
+		step = 1
+	}
+	done, stopped := 0, false
+	tick := func() {
+		done++
+		if done%step == 0 && !stopped {
+			stopped = !send(loadProgressMsg{done: done, total: total})
 		}
-	default:
-		n.Value = vv
 	}
-	return n
+	root := buildNodeWith("root", tree, tick)
+	initialCollapse(root, opts)
+	r := &treeRenderer{indent: 3, onLine: tick, comments: opts.Comments}
+	r.render(root, "", true)
+	if !stopped {
+		send(loadDoneMsg{root: root, lines: r.lines, rows: r.rows, layouts: r.layouts})
+	}
 }
 
-func renderTreeLines(n *Node, prefix string, isTail bool, indent int) []string {
-
-	var branch string
-	if isTail {
-		branch = "└" + strings.Repeat("─", indent)
-	} else {
-		branch = "├" + strings.Repeat("─", indent)
-	}
-	
-	line := prefix + branch + " " + n.Key
-	if n.Value != nil && len(n.Children) == 0 {
-		line += fmt.Sprintf(": %v", n.Value)
+// waitForLoad returns a command that delivers the next message from the
+// background build.
+func waitForLoad(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
 	}
+}
 
-	lines := []string{line}
-
-	var nextPrefix string
-	if isTail {
-		nextPrefix = prefix + strings.Repeat(" ", indent+2)
-	} else {
-		nextPrefix = prefix + "│" + strings.Repeat(" ", indent+1)
-	}
-	// recurse
-	for i, c := range n.Children {
-		childLines := renderTreeLines(c, nextPrefix, i == len(n.Children)-1, indent)
-		lines = append(lines, childLines...)
-	}
-	return lines
+// Options configures the viewer beyond the tree itself.
+type Options struct {
+	// FilePath is the file the tree was loaded from; edits are saved back to it
+	// by rewriting just the edited value in Source, and its name is shown in
+	// the title.
+	FilePath string
+	// FileSize is the size of the loaded file in bytes, shown in the title.
+	FileSize int64
+	// EditPath, when set, opens the editor on the scalar at this path of node
+	// keys, e.g. []string{"users", "[0]", "name"}.
+	EditPath []string
+	// Format serializes values for copying and exporting. It defaults to
+	// indented JSON.
+	Format func(v interface{}) string
+	// Collapsed starts with every container below the root collapsed, so only
+	// the top-level keys are shown.
+	Collapsed bool
+	// Expand, when set, starts with every container collapsed except those on
+	// the way to these paths of node keys, so the nodes they lead to show in
+	// context.
+	Expand [][]string
+	// Comments are source comments keyed by the JSON Pointer of the value they
+	// document, shown at the end of that value's line.
+	Comments map[string][]string
+	// Source is the raw file content, shown by the hex view and edited in
+	// place when saving.
+	Source []byte
+	// RevealBatch is how many lines the opening animation reveals per tick.
+	// Zero scales the batch with the file so it is fully shown in about two
+	// seconds.
+	RevealBatch int
+	// Warning is shown in a banner under the title, e.g. when the file only
+	// parsed after relaxing the grammar.
+	Warning string
 }
 
+// inputMode is what the status-bar text input is currently collecting.
+type inputMode int
+
+const (
+	inputNone      inputMode = iota
+	inputEdit                // new value for the node being edited
+	inputExport              // file name to export the selected subtree to
+	inputOverwrite           // y/n before replacing an existing export file
+	inputSearch              // text to find, matched as it is typed
+)
+
 type model struct {
 	lines     []string
 	displayed int
@@ -72,10 +143,51 @@ type model struct {
 	viewport  viewport.Model
 	ready     bool
 	style     lipgloss.Style
-}
 
+	root     *Node
+	rows     []*Node // node shown on each line
+	layouts  []lineLayout
+	cursor   int
+	hide     hideMode
+	flat     bool // show leaves as "path: value" instead of the tree
+	hex      bool // show a hex dump of Options.Source instead of the tree
+	numbered bool // show line numbers in a gutter left of the tree
+	hexLines []string
+	query    string // text searched for with /
+	matches  []int  // lines containing query, in order
+	match    int    // index into matches of the current match
+	tree     interface{}
+	nodes    int
+	loading  bool
+	loadCh   chan tea.Msg
+	loadStop chan struct{} // closed on quit so a background build stops reporting
+	percent  float64
+	progress progress.Model
 
+	opts       Options
+	format     func(v interface{}) string
+	mode       inputMode
+	input      textinput.Model
+	editing    *Node
+	exporting  *Node
+	exportPath string
+	message    string
+
+	crumbNode *Node  // node the cached breadcrumb was built for
+	crumb     string // path from the root to crumbNode
+}
+
+// NewModel returns the viewer for an already parsed JSON value. tree may be
+// any value built from map[string]interface{}, []interface{}, string,
+// float64, bool and nil, as produced by this parser or by unmarshalling into
+// an interface{} with encoding/json. Go integer and float32 values are shown
+// as numbers too, so hand-built trees work without conversion.
 func NewModel(tree interface{}) tea.Model {
+	return NewModelWithOptions(tree, Options{})
+}
+
+// NewModelWithOptions is NewModel with extra viewer configuration.
+func NewModelWithOptions(tree interface{}, opts Options) tea.Model {
 
 	vp := viewport.New(0, 0)
 
@@ -83,53 +195,289 @@ func NewModel(tree interface{}) tea.Model {
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("#7D56F4")).
 		Padding(1, 2)
-	
+
 	containerStyle := lipgloss.NewStyle().
 		Border(lipgloss.ThickBorder()).
 		BorderForeground(lipgloss.Color("#BD93F9")).
 		Margin(1, 2)
 
-	root := buildNode("root", tree)
-	allLines := renderTreeLines(root, "", true, 3)
-	return &model{
-		lines:     allLines,
+	m := &model{
 		displayed: 0,
 		indent:    3,
 		viewport:  vp,
 		ready:     false,
 		style:     containerStyle,
+		tree:      tree,
+		progress:  progress.New(progress.WithScaledGradient("#7D56F4", "#BD93F9")),
+		opts:      opts,
+		format:    opts.Format,
+		input:     textinput.New(),
+	}
+	if m.format == nil {
+		m.format = defaultFormat
+	}
+
+	// small documents are rendered instantly; big ones load behind a progress bar
+	m.nodes = countNodes(tree)
+	if m.nodes > loadThreshold {
+		m.loading = true
+	} else {
+		m.root = BuildNode("root", tree)
+		initialCollapse(m.root, opts)
+		m.rebuild()
+		m.startEdit()
 	}
+	return m
 }
 
-func (m *model) Init() tea.Cmd {
-	return tea.Tick(time.Millisecond*150, func(t time.Time) tea.Msg {
+// startEdit opens the editor on Options.EditPath once the tree is built.
+func (m *model) startEdit() {
+	if m.opts.EditPath == nil {
+		return
+	}
+	n := findNode(m.root, m.opts.EditPath)
+	if n == nil || len(n.Children) > 0 || n.Kind == KindObject || n.Kind == KindArray {
+		m.message = fmt.Sprintf("cannot edit %s: not a scalar", pathLabel(m.opts.EditPath))
+		return
+	}
+	m.editing = n
+	m.hide = showAll
+	expandPath(m.root, m.opts.EditPath)
+	m.rebuild()
+	m.displayed = len(m.lines)
+	m.cursor = m.rowOf(n)
+	m.prompt(inputEdit, editText(n))
+}
+
+// commitEdit saves the editor input to the file and, once that worked, shows
+// it in the tree.
+func (m *model) commitEdit() {
+	value, kind, err := parseEdit(m.editing.Kind, m.input.Value())
+	if err != nil {
+		m.message = err.Error()
+		return
+	}
+	n := m.editing
+	m.editing = nil
+	m.closePrompt()
+	if err := m.save(n, value); err != nil {
+		m.message = "save failed: " + err.Error()
+		return
+	}
+	n.Value = value
+	n.Kind = kind
+	m.rebuild()
+	m.message = "saved " + m.opts.FilePath
+}
+
+// prompt focuses the status-bar input for the given mode.
+func (m *model) prompt(mode inputMode, value string) {
+	m.mode = mode
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.input.Focus()
+}
+
+func (m *model) closePrompt() {
+	m.mode = inputNone
+	m.input.Blur()
+}
+
+// rebuild re-renders the lines from the tree after a change to what is shown.
+func (m *model) rebuild() {
+	// nothing has been rendered before the first build, so the reveal
+	// animation still has to run
+	revealed := m.lines != nil && m.displayed >= len(m.lines)
+	selected := m.selected()
+	if m.flat {
+		m.lines, m.rows, m.layouts = flattenLines(m.root, m.hide)
+	} else {
+		r := &treeRenderer{indent: 3, hide: m.hide, comments: m.opts.Comments}
+		r.render(m.root, "", true)
+		m.lines, m.rows, m.layouts = r.lines, r.rows, r.layouts
+	}
+	m.cursor = m.rowOf(selected)
+	m.findMatches()
+	if revealed || m.displayed > len(m.lines) {
+		m.displayed = len(m.lines)
+	}
+	m.moveCursor(0)
+}
+
+// toggleCollapse collapses or expands the container under the cursor.
+func (m *model) toggleCollapse() {
+	n := m.selected()
+	if n == nil || m.flat || len(n.Children) == 0 {
+		return
+	}
+	n.Collapsed = !n.Collapsed
+	m.rebuild()
+}
+
+// rowOf returns the line showing n, or 0 if it isn't visible.
+func (m *model) rowOf(n *Node) int {
+	for i, row := range m.rows {
+		if row == n {
+			return i
+		}
+	}
+	return 0
+}
+
+// moveCursor moves the cursor by delta, keeping it on a revealed line.
+func (m *model) moveCursor(delta int) {
+	m.cursor += delta
+	if last := min(m.displayed, len(m.lines)) - 1; m.cursor > last {
+		m.cursor = last
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// selected returns the node under the cursor.
+func (m *model) selected() *Node {
+	if m.cursor < len(m.rows) {
+		return m.rows[m.cursor]
+	}
+	return nil
+}
+
+// revealBatch is the number of lines each tick of the reveal animation adds.
+func (m *model) revealBatch() int {
+	if m.opts.RevealBatch > 0 {
+		return m.opts.RevealBatch
+	}
+	ticks := int(revealDuration / tickInterval)
+	return max(1, (len(m.lines)+ticks-1)/ticks)
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(tickInterval, func(t time.Time) tea.Msg {
 		return TickMsg(t)
 	})
 }
 
+func (m *model) Init() tea.Cmd {
+	if m.loading {
+		m.loadCh = make(chan tea.Msg, 1)
+		m.loadStop = make(chan struct{})
+		go loadTree(m.tree, m.nodes, m.opts, m.loadCh, m.loadStop)
+		return waitForLoad(m.loadCh)
+	}
+	return tick()
+}
+
+// quit ends the program, first releasing a background build still running.
+func (m *model) quit() tea.Cmd {
+	if m.loadStop != nil {
+		close(m.loadStop)
+		m.loadStop = nil
+	}
+	return tea.Quit
+}
+
+// updateInput handles keys while the status-bar input is active.
+func (m *model) updateInput(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc":
+		if m.mode == inputSearch {
+			m.clearSearch()
+		}
+		m.editing, m.exporting = nil, nil
+		m.closePrompt()
+		m.message = "cancelled"
+	case "enter":
+		switch m.mode {
+		case inputEdit:
+			m.commitEdit()
+		case inputExport:
+			m.confirmExport()
+		case inputSearch:
+			m.closePrompt()
+		}
+	default:
+		if m.mode == inputOverwrite {
+			switch msg.String() {
+			case "y", "Y":
+				m.writeExport()
+			case "n", "N":
+				m.exporting = nil
+				m.closePrompt()
+				m.message = "export cancelled"
+			}
+			return nil
+		}
+		m.input, cmd = m.input.Update(msg)
+		if m.mode == inputSearch && m.input.Value() != m.query {
+			m.setQuery(m.input.Value())
+		}
+	}
+	return cmd
+}
+
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
+	case loadProgressMsg:
+		m.percent = float64(msg.done) / float64(msg.total)
+		cmd = waitForLoad(m.loadCh)
+
+	case loadDoneMsg:
+		m.root = msg.root
+		m.lines = msg.lines
+		m.rows = msg.rows
+		m.layouts = msg.layouts
+		m.loading = false
+		m.percent = 1
+		m.startEdit()
+		cmd = tick()
+
 	case TickMsg:
 		if m.displayed < len(m.lines) {
-			m.displayed++
-			cmd = tea.Tick(time.Millisecond*150, func(t time.Time) tea.Msg {
-				return TickMsg(t)
-			})
+			m.displayed = min(m.displayed+m.revealBatch(), len(m.lines))
+			cmd = tick()
 		}
 
+	case tea.MouseMsg:
+		m.handleMouse(msg)
+
 	case tea.KeyMsg:
+		if m.mode != inputNone {
+			return m, m.updateInput(msg)
+		}
+		m.message = ""
+		if m.hex {
+			// the hex view has no cursor; movement keys scroll it instead
+			switch msg.String() {
+			case "up", "k":
+				m.viewport.ScrollUp(1)
+				return m, nil
+			case "down", "j":
+				m.viewport.ScrollDown(1)
+				return m, nil
+			case "pgup":
+				m.viewport.PageUp()
+				return m, nil
+			case "pgdown":
+				m.viewport.PageDown()
+				return m, nil
+			}
+		}
 		switch msg.String() {
 		case "q", "esc", "ctrl+c":
-			return m, tea.Quit
+			return m, m.quit()
 		case "up", "k":
-			m.viewport.LineUp(1)
+			m.moveCursor(-1)
 		case "down", "j":
-			m.viewport.LineDown(1)
+			m.moveCursor(1)
 		case "pgup":
-			m.viewport.LineUp(m.viewport.Height)
+			m.moveCursor(-m.viewport.Height)
 		case "pgdown":
-			m.viewport.LineDown(m.viewport.Height)
+			m.moveCursor(m.viewport.Height)
 		case "left", "h":
 			if m.indent > 1 {
 				m.indent--
@@ -138,53 +486,160 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.indent < 8 {
 				m.indent++
 			}
+		case "z":
+			if m.root != nil {
+				m.hide = (m.hide + 1) % (hideEmpty + 1)
+				m.rebuild()
+			}
+		case "enter", " ":
+			m.toggleCollapse()
+		case "f":
+			if m.root != nil {
+				m.flat = !m.flat
+				m.rebuild()
+			}
+		case "x":
+			m.toggleHex()
+		case "#":
+			m.numbered = !m.numbered
+		case "w":
+			if n := m.selected(); n != nil {
+				m.startExport(n)
+			}
+		case "p":
+			m.copyPath(false)
+		case "P":
+			m.copyPath(true)
+		case "y":
+			m.copyValue()
+		case "/":
+			m.startSearch()
+		case "n":
+			m.nextMatch(1)
+		case "N":
+			m.nextMatch(-1)
 		}
 
 	case tea.WindowSizeMsg:
 
 		width := msg.Width - 6
-		height := msg.Height - 6
-	
+		// one line goes to the breadcrumb under the title
+		height := msg.Height - 7
+		if m.opts.Warning != "" {
+			height--
+		}
+
 		style := m.viewport.Style
 		m.viewport = viewport.New(width, height)
 		m.viewport.Style = style
+		m.progress.Width = width - 8
 		m.ready = true
 	}
 	return m, cmd
 }
 
+// title describes the loaded document: file name, size and node count.
+func (m *model) title() string {
+	if m.opts.FilePath == "" {
+		return fmt.Sprintf(" JSON TreeView Parser  |  %d nodes ", m.nodes)
+	}
+	return fmt.Sprintf(" JSON TreeView Parser  |  %s  |  %s  |  %d nodes ",
+		filepath.Base(m.opts.FilePath), formatSize(m.opts.FileSize), m.nodes)
+}
+
+// formatSize renders a byte count with a binary unit suffix.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
 func (m *model) View() string {
 	if !m.ready {
 		return ""
 	}
-	
-	var sb strings.Builder
-	for i := 0; i < m.displayed && i < len(m.lines); i++ {
-		line := m.lines[i]
-		
-		connector := strings.Repeat("─", m.indent)
-		line = strings.ReplaceAll(line, strings.Repeat("─", 3), connector)
-		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#8BE9FD")).Render(line) + "\n")
-	}
-	m.viewport.SetContent(sb.String())
-
 
 	title := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("#FFFFFF")).
 		Background(lipgloss.Color("#555555")).
 		Padding(0, 1).
-		Render(" JSON TreeView Parser ")
+		Render(m.title())
+	if m.opts.Warning != "" {
+		title = lipgloss.JoinVertical(lipgloss.Left, title, lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#282A36")).
+			Background(lipgloss.Color("#F1FA8C")).
+			Padding(0, 1).
+			Render("⚠ "+m.opts.Warning))
+	}
 
+	if m.loading {
+		m.viewport.SetContent("Building tree...\n\n" + m.progress.ViewAs(m.percent))
+		return m.style.Render(lipgloss.JoinVertical(lipgloss.Left, title, m.viewport.View()))
+	}
+
+	if m.hex {
+		m.viewport.SetContent(strings.Join(m.hexLines, "\n"))
+		return m.style.Render(lipgloss.JoinVertical(lipgloss.Left, title, m.viewport.View(),
+			lipgloss.NewStyle().Padding(0, 1).Render(fmt.Sprintf("Hex: %d bytes  |  x: back to tree  |  q: quit", len(m.opts.Source)))))
+	}
 
+	var sb strings.Builder
+	for i := 0; i < m.displayed && i < len(m.lines); i++ {
+		lineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#8BE9FD"))
+		if m.isMatch(i) {
+			lineStyle = lineStyle.Foreground(lipgloss.Color("#F1FA8C")).Reverse(true)
+		}
+		if i == m.cursor {
+			lineStyle = lineStyle.Reverse(true).Underline(true)
+		}
+		sb.WriteString(m.gutter(i) + m.renderLine(i, lineStyle) + "\n")
+	}
+	m.viewport.SetContent(sb.String())
+	if m.cursor < m.viewport.YOffset {
+		m.viewport.SetYOffset(m.cursor)
+	} else if m.cursor >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(m.cursor - m.viewport.Height + 1)
+	}
+
+	statusText := fmt.Sprintf("Indent: %d  |  Lines: %d/%d  |  z: %s  |  enter: fold  |  /: search  |  f: flat  |  x: hex  |  #: line numbers  |  w: export  |  p: copy path  |  y: copy value  |  q: quit", m.indent, m.displayed, len(m.lines), m.hide)
+	switch m.mode {
+	case inputEdit:
+		statusText = fmt.Sprintf("Edit %s: %s  (enter: save, esc: cancel)", pathLabel(m.opts.EditPath), m.input.View())
+	case inputExport:
+		statusText = fmt.Sprintf("Export %s to: %s  (enter: write, esc: cancel)", m.exporting.Key, m.input.View())
+	case inputOverwrite:
+		statusText = fmt.Sprintf("%s exists. Overwrite? (y/n)", m.exportPath)
+	case inputSearch:
+		statusText = fmt.Sprintf("Search: %s  %s  (enter: done, esc: clear)", m.input.View(), m.searchStatus())
+	default:
+		if m.message != "" {
+			statusText = m.message + "  |  " + statusText
+		} else if search := m.searchStatus(); search != "" {
+			statusText = search + "  |  n/N: next/prev  |  " + statusText
+		}
+	}
 	status := lipgloss.NewStyle().
 		Padding(0, 1).
-		Render(fmt.Sprintf("Indent: %d  |  Lines: %d/%d  |  q: quit", m.indent, m.displayed, len(m.lines)))
+		Render(statusText)
 
+	crumb := lipgloss.NewStyle().
+		Padding(0, 1).
+		Foreground(lipgloss.Color("#BD93F9")).
+		Render(m.breadcrumb())
 
 	view := lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
+		crumb,
 		m.viewport.View(),
 		status,
 	)
@@ -0,0 +1,255 @@
+package ui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel returns a viewer for tree with every line revealed, as it is
+// once the opening animation has finished.
+func newTestModel(t *testing.T, tree interface{}, opts Options) *model {
+	t.Helper()
+	m := NewModelWithOptions(tree, opts).(*model)
+	m.displayed = len(m.lines)
+	return m
+}
+
+// selectPath moves the cursor to the node at path, failing if it isn't shown.
+func selectPath(t *testing.T, m *model, path ...string) *Node {
+	t.Helper()
+	n := findNode(m.root, path)
+	if n == nil {
+		t.Fatalf("no node at %v", path)
+	}
+	row := -1
+	for i, r := range m.rows {
+		if r == n {
+			row = i
+		}
+	}
+	if row < 0 {
+		t.Fatalf("node at %v is not on any line", path)
+	}
+	m.cursor = row
+	return n
+}
+
+// arrayOf returns an array of n small objects, about 3n nodes.
+func arrayOf(n int) []interface{} {
+	items := make([]interface{}, n)
+	for i := range items {
+		items[i] = map[string]interface{}{"id": float64(i), "name": fmt.Sprintf("item %d", i)}
+	}
+	return items
+}
+
+func TestLoadingTransitionsToTree(t *testing.T) {
+	tests := []struct {
+		name        string
+		tree        interface{}
+		opts        Options
+		wantLoading bool
+		wantEditing bool
+	}{
+		{"small tree shows at once", arrayOf(10), Options{}, false, false},
+		{"tree just under the threshold shows at once", arrayOf(loadThreshold/3 - 1), Options{}, false, false},
+		{"large tree loads in the background", arrayOf(loadThreshold), Options{}, true, false},
+		{"editor opens once the load is done", arrayOf(loadThreshold), Options{EditPath: []string{"[3]", "name"}}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModelWithOptions(tt.tree, tt.opts).(*model)
+			m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+			if m.loading != tt.wantLoading {
+				t.Fatalf("loading = %v, want %v", m.loading, tt.wantLoading)
+			}
+			cmd := m.Init()
+			if m.loading {
+				if view := m.View(); !strings.Contains(view, "Building tree...") {
+					t.Errorf("loading view lacks the progress screen:\n%s", view)
+				}
+				progress := 0
+				for m.loading {
+					msg := cmd()
+					if p, ok := msg.(loadProgressMsg); ok {
+						progress++
+						if p.done > p.total {
+							t.Fatalf("progress %d of %d", p.done, p.total)
+						}
+					} else if _, ok := msg.(loadDoneMsg); !ok {
+						t.Fatalf("unexpected message %T while loading", msg)
+					}
+					_, cmd = m.Update(msg)
+				}
+				if progress == 0 {
+					t.Error("no progress was reported")
+				}
+				if m.percent != 1 {
+					t.Errorf("percent = %v after loading", m.percent)
+				}
+			}
+			// every node of the tree is on a line once the load is done
+			if want := countNodes(tt.tree); len(m.lines) != want || len(m.rows) != want {
+				t.Errorf("%d lines and %d rows, want %d", len(m.lines), len(m.rows), want)
+			}
+			if view := m.View(); strings.Contains(view, "Building tree...") {
+				t.Error("progress screen still shown after loading")
+			}
+			if editing := m.editing != nil && m.mode == inputEdit; editing != tt.wantEditing {
+				t.Errorf("editing = %v, want %v", editing, tt.wantEditing)
+			}
+		})
+	}
+}
+
+func TestQuitWhileLoadingStopsBuild(t *testing.T) {
+	m := NewModelWithOptions(arrayOf(loadThreshold), Options{}).(*model)
+	m.Init()
+	ch := m.loadCh
+	m.quit()
+	// the build goroutine closes its channel when it exits; drain the one
+	// message it may have buffered before seeing the stop
+	for range ch {
+	}
+}
+
+func TestRevealBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     int // the tree has 3*items+1 lines
+		batch     int
+		wantTicks int
+	}{
+		{"1000 lines reveal within the target time", 333, 0, int(revealDuration / tickInterval)},
+		{"short file", 3, 0, 10},
+		{"4000 lines", 1333, 0, int(revealDuration / tickInterval)},
+		{"configured batch", 333, 50, 20},
+		{"one line per tick", 3, 1, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModelWithOptions(arrayOf(tt.items), Options{RevealBatch: tt.batch}).(*model)
+			if m.displayed != 0 {
+				t.Fatalf("%d lines shown before the first tick", m.displayed)
+			}
+			if m.Init() == nil {
+				t.Fatal("no reveal animation")
+			}
+			ticks := 0
+			for m.displayed < len(m.lines) {
+				if ticks++; ticks > len(m.lines) {
+					t.Fatalf("still revealing after %d ticks", ticks)
+				}
+				m.Update(TickMsg{})
+			}
+			if ticks != tt.wantTicks {
+				t.Errorf("took %d ticks (%v), want %d", ticks, time.Duration(ticks)*tickInterval, tt.wantTicks)
+			}
+			if elapsed := time.Duration(ticks) * tickInterval; tt.batch == 0 && elapsed > revealDuration {
+				t.Errorf("reveal took %v, longer than %v", elapsed, revealDuration)
+			}
+			if _, cmd := m.Update(TickMsg{}); cmd != nil {
+				t.Error("animation keeps ticking after every line is shown")
+			}
+		})
+	}
+}
+
+// z cycles through hiding null leaves, hiding every empty leaf, and showing
+// everything again.
+func TestHideCycle(t *testing.T) {
+	tree := map[string]interface{}{
+		"n":   nil,
+		"s":   "",
+		"a":   []interface{}{},
+		"o":   map[string]interface{}{},
+		"v":   float64(1),
+		"arr": []interface{}{nil, "x"},
+	}
+	all := []string{
+		"└─── root",
+		"     ├─── a: []",
+		"     ├─── arr",
+		"     │    ├─── [0]",
+		"     │    └─── [1]: x",
+		"     ├─── n",
+		"     ├─── o: {}",
+		"     ├─── s: ",
+		"     └─── v: 1",
+	}
+	steps := []struct {
+		name string
+		want []string
+	}{
+		{"null leaves hidden", []string{
+			"└─── root",
+			"     ├─── a: []",
+			"     ├─── arr",
+			"     │    └─── [1]: x",
+			"     ├─── o: {}",
+			"     ├─── s: ",
+			"     └─── v: 1",
+		}},
+		{"null and empty leaves hidden", []string{
+			"└─── root",
+			"     ├─── arr",
+			"     │    └─── [1]: x",
+			"     └─── v: 1",
+		}},
+		{"everything shown again", all},
+	}
+	m := newTestModel(t, tree, Options{})
+	if !reflect.DeepEqual(m.lines, all) {
+		t.Fatalf("initial lines\n%q\nwant\n%q", m.lines, all)
+	}
+	for _, step := range steps {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+		if !reflect.DeepEqual(m.lines, step.want) {
+			t.Errorf("%s: got\n%q\nwant\n%q", step.name, m.lines, step.want)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tree := map[string]interface{}{"a": float64(1), "b": []interface{}{"x"}}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"no file", Options{}, " JSON TreeView Parser  |  4 nodes "},
+		{"bytes", Options{FilePath: "/tmp/data/small.json", FileSize: 512}, " JSON TreeView Parser  |  small.json  |  512 B  |  4 nodes "},
+		{"kibibytes", Options{FilePath: "big.json", FileSize: 1536}, " JSON TreeView Parser  |  big.json  |  1.5 KiB  |  4 nodes "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTestModel(t, tree, tt.opts).title(); got != tt.want {
+				t.Errorf("title = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{1024 * 1024, "1.0 MiB"},
+		{5 << 30, "5.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.size); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
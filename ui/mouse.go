@@ -0,0 +1,47 @@
+package ui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// wheelLines is how far one wheel notch moves.
+const wheelLines = 3
+
+// handleMouse scrolls on the wheel and selects the clicked line; clicking an
+// object or array also folds or unfolds it.
+func (m *model) handleMouse(msg tea.MouseMsg) {
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		if m.hex {
+			m.viewport.ScrollUp(wheelLines)
+		} else {
+			m.moveCursor(-wheelLines)
+		}
+	case msg.Button == tea.MouseButtonWheelDown:
+		if m.hex {
+			m.viewport.ScrollDown(wheelLines)
+		} else {
+			m.moveCursor(wheelLines)
+		}
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		if m.hex || m.mode != inputNone || m.loading {
+			return
+		}
+		line := msg.Y - m.treeTop() + m.viewport.YOffset
+		if msg.Y < m.treeTop() || line >= min(m.displayed, len(m.lines)) {
+			return
+		}
+		m.message = ""
+		m.cursor = line
+		m.toggleCollapse()
+	}
+}
+
+// treeTop is the screen row of the first line inside the viewport: below the
+// outer margin and border, the title, the warning banner if any, the
+// breadcrumb and the viewport's own border and padding.
+func (m *model) treeTop() int {
+	top := m.style.GetMarginTop() + m.style.GetBorderTopSize() + 2
+	if m.opts.Warning != "" {
+		top++
+	}
+	return top + m.viewport.Style.GetBorderTopSize() + m.viewport.Style.GetPaddingTop()
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// startSearch opens the search input, prefilled with the last query.
+func (m *model) startSearch() {
+	if m.root == nil || m.hex {
+		return
+	}
+	// every line must be reachable to jump to a match
+	m.displayed = len(m.lines)
+	m.prompt(inputSearch, m.query)
+}
+
+// setQuery updates the query as it is typed and moves to the first match at
+// or after the cursor.
+func (m *model) setQuery(query string) {
+	m.query = query
+	m.findMatches()
+	m.match = 0
+	for i, line := range m.matches {
+		if line >= m.cursor {
+			m.match = i
+			break
+		}
+	}
+	m.jumpToMatch()
+}
+
+// findMatches collects the lines containing the query, ignoring case.
+func (m *model) findMatches() {
+	m.matches = nil
+	if m.query == "" {
+		return
+	}
+	query := strings.ToLower(m.query)
+	for i, line := range m.lines {
+		if strings.Contains(strings.ToLower(line), query) {
+			m.matches = append(m.matches, i)
+		}
+	}
+	if m.match >= len(m.matches) {
+		m.match = 0
+	}
+}
+
+// nextMatch moves the cursor delta matches forward, wrapping around.
+func (m *model) nextMatch(delta int) {
+	if len(m.matches) == 0 {
+		if m.query != "" {
+			m.message = fmt.Sprintf("no matches for %q", m.query)
+		}
+		return
+	}
+	m.match = (m.match + delta + len(m.matches)) % len(m.matches)
+	m.jumpToMatch()
+}
+
+// jumpToMatch puts the cursor on the current match.
+func (m *model) jumpToMatch() {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.displayed = len(m.lines)
+	m.cursor = m.matches[m.match]
+}
+
+// clearSearch drops the query and its highlights.
+func (m *model) clearSearch() {
+	m.query, m.matches, m.match = "", nil, 0
+}
+
+// isMatch reports whether line i contains the query.
+func (m *model) isMatch(i int) bool {
+	j := sort.SearchInts(m.matches, i)
+	return j < len(m.matches) && m.matches[j] == i
+}
+
+// searchStatus describes the search position, e.g. "match 3/17".
+func (m *model) searchStatus() string {
+	if m.query == "" {
+		return ""
+	}
+	if len(m.matches) == 0 {
+		return fmt.Sprintf("no matches for %q", m.query)
+	}
+	return fmt.Sprintf("match %d/%d", m.match+1, len(m.matches))
+}
@@ -0,0 +1,404 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+// NodeKind is the JSON type of the value a Node was built from.
+type NodeKind int
+
+const (
+	KindNull NodeKind = iota
+	KindString
+	KindNumber
+	KindBool
+	KindObject
+	KindArray
+)
+
+// Node is one entry of the viewer's tree: the root, an object member or an
+// array element.
+type Node struct {
+	Key      string
+	Value    interface{}
+	Kind     NodeKind
+	Children []*Node
+	// Collapsed hides the children of an object or array in the tree view.
+	Collapsed bool
+}
+
+// hideMode controls which leaves are left out of the rendered tree.
+type hideMode int
+
+const (
+	showAll   hideMode = iota
+	hideNull           // null leaves
+	hideEmpty          // null leaves plus empty strings, arrays and objects
+)
+
+func (h hideMode) String() string {
+	switch h {
+	case hideNull:
+		return "nulls hidden"
+	case hideEmpty:
+		return "nulls+empty hidden"
+	}
+	return "all shown"
+}
+
+// hides reports whether n is left out of the tree under this mode.
+func (h hideMode) hides(n *Node) bool {
+	switch h {
+	case hideNull:
+		return n.Kind == KindNull
+	case hideEmpty:
+		switch n.Kind {
+		case KindNull:
+			return true
+		case KindString:
+			return n.Value == ""
+		case KindObject, KindArray:
+			return len(n.Children) == 0
+		}
+	}
+	return false
+}
+
+// visibleChildren returns the children of n that mode doesn't hide.
+func (h hideMode) visibleChildren(n *Node) []*Node {
+	if h == showAll {
+		return n.Children
+	}
+	var out []*Node
+	for _, c := range n.Children {
+		if !h.hides(c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// BuildNode converts a parsed JSON value into a Node tree. Objects and arrays
+// become nodes with children (array elements keyed "[0]", "[1]", ...);
+// scalars become leaves holding the value. Object members are in sorted key
+// order, so a document always gives the same tree.
+func BuildNode(key string, v interface{}) *Node {
+	return buildNodeWith(key, v, nil)
+}
+
+func buildNodeWith(key string, v interface{}, onNode func()) *Node {
+	if onNode != nil {
+		onNode()
+	}
+	n := &Node{Key: key}
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		n.Kind = KindObject
+		keys := make([]string, 0, len(vv))
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			n.Children = append(n.Children, buildNodeWith(k, vv[k], onNode))
+		}
+	case []interface{}:
+		n.Kind = KindArray
+		for i, val := range vv {
+			n.Children = append(n.Children, buildNodeWith(fmt.Sprintf("[%d]", i), val, onNode))
+		}
+	case []float64:
+		// typed arrays from the parser's TypedSlices option
+		n.Kind = KindArray
+		for i, val := range vv {
+			n.Children = append(n.Children, buildNodeWith(fmt.Sprintf("[%d]", i), val, onNode))
+		}
+	case []int64:
+		n.Kind = KindArray
+		for i, val := range vv {
+			n.Children = append(n.Children, buildNodeWith(fmt.Sprintf("[%d]", i), val, onNode))
+		}
+	case []string:
+		n.Kind = KindArray
+		for i, val := range vv {
+			n.Children = append(n.Children, buildNodeWith(fmt.Sprintf("[%d]", i), val, onNode))
+		}
+	case json.RawMessage:
+		// shown as the value it encodes; invalid JSON is kept as text
+		var decoded interface{}
+		if err := json.Unmarshal(vv, &decoded); err != nil {
+			n.Kind = KindString
+			n.Value = string(vv)
+			break
+		}
+		built := buildNodeWith(key, decoded, nil)
+		n.Kind, n.Value, n.Children = built.Kind, built.Value, built.Children
+	default:
+		n.Value = vv
+		switch vv.(type) {
+		case string:
+			n.Kind = KindString
+		case float64, float32, json.Number, int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64:
+			n.Kind = KindNumber
+		case bool:
+			n.Kind = KindBool
+		}
+	}
+	return n
+}
+
+// collapseAll collapses every object and array below n, leaving n itself
+// expanded so its direct children stay visible.
+func collapseAll(n *Node) {
+	for _, c := range n.Children {
+		if len(c.Children) > 0 {
+			c.Collapsed = true
+		}
+		collapseAll(c)
+	}
+}
+
+// initialCollapse sets up the collapse state the viewer opens with.
+func initialCollapse(root *Node, opts Options) {
+	if opts.Collapsed || opts.Expand != nil {
+		collapseAll(root)
+	}
+	for _, path := range opts.Expand {
+		expandPath(root, path)
+	}
+}
+
+// previewKeys and previewWidth bound the summary shown for collapsed nodes.
+const (
+	previewKeys  = 3
+	previewWidth = 40
+)
+
+// collapsedPreview summarizes a collapsed container on its line: the first
+// few child keys of an object, like {name, age, …}, or the first element of
+// an array, like [42, …]. Long previews are cut to previewWidth runes.
+func collapsedPreview(n *Node) string {
+	var parts []string
+	open, close := "{", "}"
+	if n.Kind == KindArray {
+		open, close = "[", "]"
+		first := n.Children[0]
+		switch first.Kind {
+		case KindObject:
+			parts = append(parts, "{…}")
+		case KindArray:
+			parts = append(parts, "[…]")
+		case KindString:
+			parts = append(parts, strconv.Quote(first.Value.(string)))
+		case KindNull:
+			parts = append(parts, "null")
+		default:
+			parts = append(parts, formatValue(first.Value))
+		}
+	} else {
+		for _, c := range n.Children[:min(previewKeys, len(n.Children))] {
+			parts = append(parts, c.Key)
+		}
+	}
+	if len(parts) < len(n.Children) {
+		parts = append(parts, "…")
+	}
+	preview := []rune(strings.Join(parts, ", "))
+	if len(preview) > previewWidth {
+		preview = append(preview[:previewWidth-1], '…')
+	}
+	return open + string(preview) + close
+}
+
+// formatValue renders a leaf value for display.
+func formatValue(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return jsonparser.FormatNumber(f)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// countNodes returns the number of nodes BuildNode will create for v.
+func countNodes(v interface{}) int {
+	count := 1
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for _, val := range vv {
+			count += countNodes(val)
+		}
+	case []interface{}:
+		for _, val := range vv {
+			count += countNodes(val)
+		}
+	case []float64:
+		count += len(vv)
+	case []int64:
+		count += len(vv)
+	case []string:
+		count += len(vv)
+	}
+	return count
+}
+
+// RenderOptions controls how RenderTreeLines draws a tree.
+type RenderOptions struct {
+	// Indent is the number of dashes in each branch connector. Zero means 3.
+	Indent int
+	// Prefix is written before the branch glyphs of every line.
+	Prefix string
+	// HideNull leaves out null leaves.
+	HideNull bool
+	// HideEmpty leaves out null leaves and empty strings, arrays and objects.
+	HideEmpty bool
+	// Style, when set, renders every line with it.
+	Style *lipgloss.Style
+}
+
+// RenderTreeLines draws n and its descendants as box-drawing tree lines,
+// one per node, in the same layout as the bundled viewer.
+func RenderTreeLines(n *Node, opts RenderOptions) []string {
+	r := &treeRenderer{indent: opts.Indent}
+	if r.indent <= 0 {
+		r.indent = 3
+	}
+	switch {
+	case opts.HideEmpty:
+		r.hide = hideEmpty
+	case opts.HideNull:
+		r.hide = hideNull
+	}
+	r.render(n, opts.Prefix, true)
+	if opts.Style != nil {
+		for i, line := range r.lines {
+			r.lines[i] = opts.Style.Render(line)
+		}
+	}
+	return r.lines
+}
+
+// treeRenderer turns a Node tree into display lines, remembering which node
+// each line shows so the cursor can be mapped back to the tree.
+type treeRenderer struct {
+	indent   int
+	hide     hideMode
+	onLine   func()
+	comments map[string][]string // shown after the node with that JSON Pointer
+	chain    []*Node             // nodes from the root down to the one being rendered
+	lines    []string
+	rows     []*Node
+	layouts  []lineLayout
+}
+
+// lineLayout locates the parts of a rendered line that are colored by type.
+type lineLayout struct {
+	key   int // byte offset where the key starts
+	value int // byte offset where the leaf value starts, or -1 if none is shown
+	end   int // byte offset just past the value
+}
+
+func (r *treeRenderer) render(n *Node, prefix string, isTail bool) {
+	if r.onLine != nil {
+		r.onLine()
+	}
+	indent := r.indent
+
+	var branch string
+	if isTail {
+		branch = "└" + strings.Repeat("─", indent)
+	} else {
+		branch = "├" + strings.Repeat("─", indent)
+	}
+
+	line := prefix + branch + " "
+	layout := lineLayout{key: len(line), value: -1}
+	line += n.Key
+	if len(n.Children) == 0 && (n.Value != nil || n.Kind == KindObject || n.Kind == KindArray) {
+		line += ": "
+		layout.value = len(line)
+		switch n.Kind {
+		case KindObject:
+			// empty containers show their brackets so they can't pass for null
+			line += "{}"
+		case KindArray:
+			line += "[]"
+		default:
+			line += formatValue(n.Value)
+		}
+	}
+	layout.end = len(line)
+	if n.Collapsed && len(n.Children) > 0 {
+		line += " " + collapsedPreview(n)
+	}
+	if r.comments != nil {
+		r.chain = append(r.chain, n)
+		defer func() { r.chain = r.chain[:len(r.chain)-1] }()
+		if c := r.comments[jsonPointer(r.chain)]; len(c) > 0 {
+			line += "  // " + strings.Join(c, "; ")
+		}
+	}
+
+	r.lines = append(r.lines, line)
+	r.rows = append(r.rows, n)
+	r.layouts = append(r.layouts, layout)
+
+	var nextPrefix string
+	if isTail {
+		nextPrefix = prefix + strings.Repeat(" ", indent+2)
+	} else {
+		nextPrefix = prefix + "│" + strings.Repeat(" ", indent+1)
+	}
+	if n.Collapsed {
+		return
+	}
+	// recurse
+	children := r.hide.visibleChildren(n)
+	for i, c := range children {
+		r.render(c, nextPrefix, i == len(children)-1)
+	}
+}
+
+// flattenLines renders each visible leaf as its dotted path and value on one
+// line, e.g. "users[0].address.city: Paris", for deep or wide documents.
+func flattenLines(root *Node, hide hideMode) ([]string, []*Node, []lineLayout) {
+	var lines []string
+	var rows []*Node
+	var layouts []lineLayout
+	var visit func(n *Node, path []string)
+	visit = func(n *Node, path []string) {
+		children := hide.visibleChildren(n)
+		if len(children) > 0 {
+			for _, c := range children {
+				visit(c, append(path, c.Key))
+			}
+			return
+		}
+		label := pathLabel(path)
+		if label == "" {
+			label = n.Key
+		}
+		var value string
+		switch n.Kind {
+		case KindObject:
+			value = "{}"
+		case KindArray:
+			value = "[]"
+		case KindNull:
+			value = "null"
+		default:
+			value = formatValue(n.Value)
+		}
+		lines = append(lines, label+": "+value)
+		rows = append(rows, n)
+		layouts = append(layouts, lineLayout{key: 0, value: len(label) + 2, end: len(label) + 2 + len(value)})
+	}
+	visit(root, nil)
+	return lines, rows, layouts
+}
@@ -0,0 +1,335 @@
+package ui
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestRenderTreeLines(t *testing.T) {
+	tree := []interface{}{nil, "", []interface{}{}, float64(1), []interface{}{"x"}}
+	bold := lipgloss.NewStyle().Bold(true)
+	tests := []struct {
+		name string
+		opts RenderOptions
+		want []string
+	}{
+		{"defaults", RenderOptions{}, []string{
+			"└─── root",
+			"     ├─── [0]",
+			"     ├─── [1]: ",
+			"     ├─── [2]: []",
+			"     ├─── [3]: 1",
+			"     └─── [4]",
+			"          └─── [0]: x",
+		}},
+		{"indent and prefix", RenderOptions{Indent: 1, Prefix: "> "}, []string{
+			"> └─ root",
+			">    ├─ [0]",
+			">    ├─ [1]: ",
+			">    ├─ [2]: []",
+			">    ├─ [3]: 1",
+			">    └─ [4]",
+			">       └─ [0]: x",
+		}},
+		{"hide null", RenderOptions{HideNull: true}, []string{
+			"└─── root",
+			"     ├─── [1]: ",
+			"     ├─── [2]: []",
+			"     ├─── [3]: 1",
+			"     └─── [4]",
+			"          └─── [0]: x",
+		}},
+		{"hide empty", RenderOptions{HideEmpty: true}, []string{
+			"└─── root",
+			"     ├─── [3]: 1",
+			"     └─── [4]",
+			"          └─── [0]: x",
+		}},
+		{"style", RenderOptions{Style: &bold}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderTreeLines(BuildNode("root", tree), tt.opts)
+			if tt.want == nil {
+				plain := RenderTreeLines(BuildNode("root", tree), RenderOptions{})
+				tt.want = make([]string, len(plain))
+				for i, line := range plain {
+					tt.want[i] = bold.Render(line)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// The bundled viewer draws its tree with the same renderer, so the exported
+// API must produce the lines it shows.
+func TestRenderTreeLinesMatchesViewer(t *testing.T) {
+	tree := map[string]interface{}{
+		"name": "x",
+		"tags": []interface{}{"a", nil, ""},
+		"meta": map[string]interface{}{"empty": map[string]interface{}{}, "n": nil},
+	}
+	tests := []struct {
+		name string
+		hide hideMode
+		opts RenderOptions
+	}{
+		{"show all", showAll, RenderOptions{}},
+		{"hide null", hideNull, RenderOptions{HideNull: true}},
+		{"hide empty", hideEmpty, RenderOptions{HideEmpty: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, tree, Options{})
+			m.hide = tt.hide
+			m.rebuild()
+			if got := RenderTreeLines(m.root, tt.opts); !reflect.DeepEqual(got, m.lines) {
+				t.Errorf("RenderTreeLines\n%q\nviewer\n%q", got, m.lines)
+			}
+		})
+	}
+}
+
+func TestBuildNodeValueShapes(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		wantKind  NodeKind
+		wantText  string // formatted leaf value ("" for containers)
+		wantChild int
+	}{
+		{"null", nil, KindNull, "", 0},
+		{"float64", float64(2.5), KindNumber, "2.5", 0},
+		{"large float64", float64(1e21), KindNumber, "1e+21", 0},
+		{"Go int", 7, KindNumber, "7", 0},
+		{"Go int64", int64(9007199254740993), KindNumber, "9007199254740993", 0},
+		{"Go uint8", uint8(200), KindNumber, "200", 0},
+		{"float32", float32(1.5), KindNumber, "1.5", 0},
+		{"json.Number", json.Number("12345678901234567890"), KindNumber, "12345678901234567890", 0},
+		{"string", "text", KindString, "text", 0},
+		{"bool", true, KindBool, "true", 0},
+		{"object", map[string]interface{}{"a": 1, "b": 2}, KindObject, "", 2},
+		{"array", []interface{}{1, "x", nil}, KindArray, "", 3},
+		{"typed float slice", []float64{1, 2}, KindArray, "", 2},
+		{"typed int slice", []int64{1, 2, 3}, KindArray, "", 3},
+		{"typed string slice", []string{"a"}, KindArray, "", 1},
+		{"raw message", json.RawMessage(`{"x": [1, 2]}`), KindObject, "", 1},
+		{"invalid raw message", json.RawMessage(`{oops`), KindString, "{oops", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := BuildNode("root", tt.value)
+			if n.Kind != tt.wantKind || len(n.Children) != tt.wantChild {
+				t.Fatalf("kind %v with %d children, want %v with %d", n.Kind, len(n.Children), tt.wantKind, tt.wantChild)
+			}
+			if tt.wantText != "" {
+				if got := formatValue(n.Value); got != tt.wantText {
+					t.Errorf("shown as %q, want %q", got, tt.wantText)
+				}
+			}
+			// countNodes only sizes the background load and doesn't decode raw messages
+			if _, raw := tt.value.(json.RawMessage); !raw && countNodes(tt.value) != 1+tt.wantChild {
+				t.Errorf("countNodes = %d, want %d", countNodes(tt.value), 1+tt.wantChild)
+			}
+		})
+	}
+}
+
+func TestNewModelFromEncodingJSON(t *testing.T) {
+	const doc = `{"users": [{"id": 1, "name": "ann", "admin": true, "manager": null}], "total": 1.5}`
+	tests := []struct {
+		name      string
+		useNumber bool
+	}{
+		{"float64 numbers", false},
+		{"json.Number numbers", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := json.NewDecoder(strings.NewReader(doc))
+			if tt.useNumber {
+				dec.UseNumber()
+			}
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				t.Fatal(err)
+			}
+			m := newTestModel(t, v, Options{})
+			text := strings.Join(m.lines, "\n")
+			for _, want := range []string{"users", "[0]", "id: 1", "name: ann", "admin: true", "total: 1.5"} {
+				if !strings.Contains(text, want) {
+					t.Errorf("viewer lines lack %q:\n%s", want, text)
+				}
+			}
+			if n := findNode(m.root, []string{"users", "[0]", "manager"}); n == nil || n.Kind != KindNull {
+				t.Errorf("manager node = %+v, want a null", n)
+			}
+			if m.nodes != 8 {
+				t.Errorf("nodes = %d, want 8", m.nodes)
+			}
+		})
+	}
+}
+
+// expandedContainers lists the paths of the objects and arrays below n that
+// show their children, in sorted order.
+func expandedContainers(n *Node, path []string) []string {
+	var out []string
+	for _, c := range n.Children {
+		p := append(append([]string{}, path...), c.Key)
+		if len(c.Children) > 0 && !c.Collapsed {
+			out = append(out, pathLabel(p))
+		}
+		out = append(out, expandedContainers(c, p)...)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestInitialExpand(t *testing.T) {
+	tree := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "ann", "tags": []interface{}{"x"}},
+			map[string]interface{}{"name": "bob", "tags": []interface{}{"y"}},
+		},
+		"meta": map[string]interface{}{"v": 1.0},
+	}
+	all := []string{"meta", "users", "users[0]", "users[0].tags", "users[1]", "users[1].tags"}
+	tests := []struct {
+		name string
+		tree interface{}
+		opts Options
+		want []string
+	}{
+		{"everything open by default", tree, Options{}, all},
+		{"collapsed", tree, Options{Collapsed: true}, nil},
+		{"no matches collapses everything", tree, Options{Expand: [][]string{}}, nil},
+		{"one leaf", tree, Options{Expand: [][]string{{"users", "[1]", "name"}}}, []string{"users", "users[1]"}},
+		{"several branches", tree, Options{Expand: [][]string{{"users", "[0]", "tags", "[0]"}, {"meta", "v"}}},
+			[]string{"meta", "users", "users[0]", "users[0].tags"}},
+		{"a matched container stays closed", tree, Options{Expand: [][]string{{"users", "[0]"}}}, []string{"users"}},
+		{"expand wins over collapsed", tree, Options{Collapsed: true, Expand: [][]string{{"meta", "v"}}}, []string{"meta"}},
+		{"unknown path", tree, Options{Expand: [][]string{{"nope", "x"}}}, nil},
+		{"background load", arrayOf(loadThreshold), Options{Expand: [][]string{{"[7]", "name"}}}, []string{"[7]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModelWithOptions(tt.tree, tt.opts).(*model)
+			if m.loading {
+				for cmd := m.Init(); m.loading; {
+					_, cmd = m.Update(cmd())
+				}
+			}
+			if got := expandedContainers(m.root, nil); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expanded %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollapsedPreview(t *testing.T) {
+	long := strings.Repeat("k", 30)
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"object keys in sorted order", map[string]interface{}{"name": "ann", "age": 3.0}, "{age, name}"},
+		{"only the first keys", map[string]interface{}{"d": 1.0, "c": 1.0, "b": 1.0, "a": 1.0}, "{a, b, c, …}"},
+		{"exactly previewKeys keys", map[string]interface{}{"c": 1.0, "a": 1.0, "b": 1.0}, "{a, b, c}"},
+		{"first number", []interface{}{42.0, 7.0}, "[42, …]"},
+		{"single element", []interface{}{42.0}, "[42]"},
+		{"quoted string", []interface{}{"x y", "z"}, `["x y", …]`},
+		{"null", []interface{}{nil, 1.0}, "[null, …]"},
+		{"boolean", []interface{}{true}, "[true]"},
+		{"nested object", []interface{}{map[string]interface{}{"a": 1.0}, 2.0}, "[{…}, …]"},
+		{"nested array", []interface{}{[]interface{}{1.0}}, "[[…]]"},
+		{"cut at previewWidth", map[string]interface{}{long + "1": 1.0, long + "2": 1.0},
+			"{" + long + "1, " + strings.Repeat("k", previewWidth-len(long)-4) + "…}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Building the same value again gives the same preview.
+			for run := 0; run < 10; run++ {
+				if got := collapsedPreview(BuildNode("v", tt.value)); got != tt.want {
+					t.Fatalf("run %d: collapsedPreview = %q, want %q", run, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFlattenLines(t *testing.T) {
+	doc := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{
+				"name":    "Ann",
+				"address": map[string]interface{}{"city": "Paris", "zip": nil},
+				"tags":    []interface{}{},
+			},
+		},
+		"count": float64(1),
+		"meta":  map[string]interface{}{},
+	}
+	tests := []struct {
+		name string
+		tree interface{}
+		hide hideMode
+		want []string
+	}{
+		{"nested document", doc, showAll, []string{
+			"count: 1",
+			"meta: {}",
+			"users[0].address.city: Paris",
+			"users[0].address.zip: null",
+			"users[0].name: Ann",
+			"users[0].tags: []",
+		}},
+		{"hide null", doc, hideNull, []string{
+			"count: 1",
+			"meta: {}",
+			"users[0].address.city: Paris",
+			"users[0].name: Ann",
+			"users[0].tags: []",
+		}},
+		{"hide empty", doc, hideEmpty, []string{
+			"count: 1",
+			"users[0].address.city: Paris",
+			"users[0].name: Ann",
+		}},
+		{"root array", []interface{}{float64(1), []interface{}{"x"}}, showAll, []string{
+			"[0]: 1",
+			"[1][0]: x",
+		}},
+		{"root scalar is labelled with the root key", "hello", showAll, []string{"root: hello"}},
+		{"empty root object", map[string]interface{}{}, showAll, []string{"root: {}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, rows, layouts := flattenLines(BuildNode("root", tt.tree), tt.hide)
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Fatalf("got\n%q\nwant\n%q", lines, tt.want)
+			}
+			if len(rows) != len(lines) || len(layouts) != len(lines) {
+				t.Fatalf("%d lines, %d rows, %d layouts", len(lines), len(rows), len(layouts))
+			}
+			for i, line := range lines {
+				label, value, _ := strings.Cut(line, ": ")
+				if got := line[layouts[i].value:layouts[i].end]; got != value {
+					t.Errorf("line %q: layout spans value %q, want %q", line, got, value)
+				}
+				if !strings.HasSuffix(label, rows[i].Key) {
+					t.Errorf("line %q is for node %q", line, rows[i].Key)
+				}
+			}
+		})
+	}
+}
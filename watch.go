@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+// /**
+// * @brief Calls onChange whenever the file at path changes, until tick reports the watch is over.
+// *
+// * @details The file is checked on every tick for a change in size or modification time. A burst of
+// * changes, as editors produce when saving, fires onChange once, after the file has been quiet for
+// * debounce, as measured by the tick times. A missing file is treated as an ordinary state, so a save that replaces the file counts
+// * as a change.
+// *
+// * @param path The file to watch.
+// * @param tick Blocks until the file should next be checked, then returns the current time, or false
+// *             to end the watch. Tests pass a fake clock here; the CLI reads a time.Ticker.
+// * @param debounce How long the file must stay unchanged before onChange runs.
+// * @param onChange The function to call after each settled change.
+// */
+func watchFile(path string, tick func() (time.Time, bool), debounce time.Duration, onChange func()) {
+	stat := func() (int64, time.Time) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return -1, time.Time{}
+		}
+		return info.Size(), info.ModTime()
+	}
+	size, mod := stat()
+	var changed time.Time ///< when the latest unreported change was seen (zero if none)
+
+	for {
+		now, ok := tick()
+		if !ok {
+			return
+		}
+		if s, m := stat(); s != size || !m.Equal(mod) {
+			size, mod = s, m
+			changed = now
+			continue
+		}
+		if !changed.IsZero() && now.Sub(changed) >= debounce {
+			changed = time.Time{}
+			onChange()
+		}
+	}
+}
+
+// /**
+// * @brief Reads and parses a file, then prints every result of the query on its own.
+// *
+// * @param w The writer receiving the results.
+// * @param path The JSON file to read.
+// * @param expr The query expression, e.g. ".users[].name".
+// * @return Any read, parse or query error.
+// */
+func printQuery(w io.Writer, path, expr string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	value, err := jsonparser.ParseJSONWithOptions(string(data), jsonparser.ParseOptions{AllowComments: true, PreserveIntegers: true})
+	if err != nil {
+		return err
+	}
+	results, err := jsonparser.Query(value, expr)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if _, err := fmt.Fprint(w, jsonparser.PrettyPrintln(result)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// /**
+// * @brief Reads and parses a file, then prints the value a JSON Pointer refers to.
+// *
+// * @param w The writer receiving the value.
+// * @param path The JSON file to read.
+// * @param pointer The RFC 6901 JSON Pointer, e.g. "/users/0".
+// * @return Any read or parse error, or an error if the pointer doesn't resolve.
+// */
+func printPointer(w io.Writer, path, pointer string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	value, err := jsonparser.ParseJSONWithOptions(string(data), jsonparser.ParseOptions{AllowComments: true, PreserveIntegers: true})
+	if err != nil {
+		return err
+	}
+	result, err := jsonparser.Get(value, pointer)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, jsonparser.PrettyPrintln(result))
+	return err
+}
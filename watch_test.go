@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itsadijmbt/JsonParser/jsonparser"
+)
+
+// writeTemp writes content to a new file in a test's temporary directory and returns its path.
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPrintPointerAndQuery(t *testing.T) {
+	path := writeTemp(t, "doc.json", `{
+  // ids are too large for a float64
+  "users": [{"id": 9007199254740993, "name": "a"}, {"id": 2}]
+}`)
+	tests := []struct {
+		name  string
+		print func(*strings.Builder) error
+		want  string
+	}{
+		{"pointer keeps integer digits", func(sb *strings.Builder) error {
+			return printPointer(sb, path, "/users/0/id")
+		}, "9007199254740993\n"},
+		{"pointer to object", func(sb *strings.Builder) error {
+			return printPointer(sb, path, "/users/1")
+		}, "{\n  \"id\": 2\n}\n"},
+		{"query keeps integer digits", func(sb *strings.Builder) error {
+			return printQuery(sb, path, ".users[].id")
+		}, "9007199254740993\n2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tt.print(&sb); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchFileDebounce(t *testing.T) {
+	path := writeTemp(t, "doc.json", `{"n": 0}`)
+	write := func(content string) func() {
+		return func() {
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	remove := func() {
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	steps := []struct {
+		name   string
+		at     time.Duration // time of the tick after the change
+		change func()
+		want   string // query output printed so far
+	}{
+		{"no change", 0, nil, ""},
+		{"save", 100 * time.Millisecond, write(`{"n": 1}`), ""},
+		{"still settling", 200 * time.Millisecond, nil, ""},
+		{"second save in the burst", 300 * time.Millisecond, write(`{"n": 12}`), ""},
+		{"quiet but within debounce", 500 * time.Millisecond, nil, ""},
+		{"settled", 600 * time.Millisecond, nil, "12\n"},
+		{"no further run", 2 * time.Second, nil, "12\n"},
+		{"another save", 2100 * time.Millisecond, write(`{"n": 123}`), "12\n"},
+		{"settled again", 2400 * time.Millisecond, nil, "12\n123\n"},
+		{"file removed", 2500 * time.Millisecond, remove, "12\n123\n"},
+		{"removal settled", 2800 * time.Millisecond, nil, "12\n123\nerror\n"},
+	}
+
+	// The fake clock hands out one time per step. watchFile only asks for the
+	// next tick once it has handled the last, so after idle is received the
+	// file can be changed and the output checked without racing it.
+	var out strings.Builder
+	ticks := make(chan time.Time)
+	idle := make(chan struct{})
+	done := make(chan struct{})
+	tick := func() (time.Time, bool) {
+		idle <- struct{}{}
+		now, ok := <-ticks
+		return now, ok
+	}
+	go func() {
+		defer close(done)
+		watchFile(path, tick, 300*time.Millisecond, func() {
+			if err := printQuery(&out, path, ".n"); err != nil {
+				out.WriteString("error\n")
+			}
+		})
+	}()
+
+	start := time.Unix(1700000000, 0)
+	<-idle
+	for _, step := range steps {
+		if step.change != nil {
+			step.change()
+		}
+		ticks <- start.Add(step.at)
+		<-idle
+		if got := out.String(); got != step.want {
+			t.Errorf("after %s: output %q, want %q", step.name, got, step.want)
+			break
+		}
+	}
+	close(ticks)
+	<-done
+}
+
+func TestPrintPointer(t *testing.T) {
+	path := writeTemp(t, "doc.json", `{
+  "users": [
+    {"name": "ann", "address": {"city": "Oslo", "zip": "0150"}},
+    {"name": "bob", "tags": ["x"]}
+  ],
+  "a/b": {"~k": true}
+}`)
+	tests := []struct {
+		name    string
+		path    string
+		pointer string
+		want    string
+		wantErr string
+	}{
+		{"nested object subtree", path, "/users/0/address",
+			"{\n  \"city\": \"Oslo\",\n  \"zip\": \"0150\"\n}\n", ""},
+		{"array element", path, "/users/1", "{\n  \"name\": \"bob\",\n  \"tags\": [\n    \"x\"\n  ]\n}\n", ""},
+		{"scalar", path, "/users/1/name", "\"bob\"\n", ""},
+		{"escaped tokens", path, "/a~1b/~0k", "true\n", ""},
+		{"missing member", path, "/users/0/phone", "", "phone"},
+		{"index out of range", path, "/users/2", "", "2"},
+		{"pointer without a slash", path, "users", "", "pointer"},
+		{"missing file", filepath.Join(t.TempDir(), "none.json"), "/users", "", "no such file"},
+		{"invalid JSON", writeTemp(t, "bad.json", `{"a": }`), "/a", "", "line 1, col 7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := printPointer(&sb, tt.path, tt.pointer)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("got error %v, want one mentioning %q", err, tt.wantErr)
+			}
+			if tt.wantErr != "" {
+				if sb.Len() > 0 {
+					t.Errorf("printed %q before failing", sb.String())
+				}
+				return
+			}
+			// PrettyPrint keeps Go's map order, so objects are compared as values.
+			got, err := jsonparser.ParseJSON(sb.String())
+			if err != nil {
+				t.Fatalf("printed invalid JSON %q: %v", sb.String(), err)
+			}
+			want, err := jsonparser.ParseJSON(tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonparser.Equal(got, want) || len(sb.String()) != len(tt.want) {
+				t.Errorf("printed %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}